@@ -5,39 +5,67 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 
 	"github.com/nekowawolf/aicraft-bot/wallet"
 )
 
-const (
-	baseURL = "https://api.aicraft.fun"
-)
-
-func WalletSignIn(signer wallet.Signer) (string, error) {
+func (c *Client) WalletSignIn(signer wallet.Signer) (string, error) {
 	address := signer.GetAddress()
 	
-	message, err := getSignMessage(address)
+	message, err := c.SignInMessage(address)
 	if err != nil {
 		return "", fmt.Errorf("failed to get sign message: %v", err)
 	}
+	slog.Debug("received sign-in message", "address", address, "message", message)
 
-	signature, err := signer.SignMessage(message)
+	if err := CheckSignInMessage(c.SignInFormat, message, c.signInExpectations(address)); err != nil {
+		return "", err
+	}
+
+	signature, err := c.signSignInMessage(signer, message)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign message: %v", err)
 	}
+	slog.Debug("signed sign-in message", "address", address, "signature", signature)
 
-	token, err := authenticate(address, message, signature)
+	token, err := c.authenticate(address, message, signature)
 	if err != nil {
 		return "", fmt.Errorf("failed to authenticate: %v", err)
 	}
+	slog.Debug("sign-in succeeded", "address", address, "token", token)
 
 	return token, nil
 }
 
-func getSignMessage(walletAddress string) (string, error) {
-	url := fmt.Sprintf("%s/auths/wallets/sign-in/message?address=%s&type=ETHEREUM_BASED", baseURL, walletAddress)
-	resp, err := http.Get(url)
+func (c *Client) signInExpectations(address string) SignInExpectations {
+	want := SignInExpectations{Address: address, ChainID: c.ChainID, Domain: c.SignInDomain}
+	if u, err := url.Parse(c.BaseURL); err == nil && want.Domain == "" {
+		want.Domain = u.Host
+	}
+	return want
+}
+
+// signSignInMessage signs message according to c.SignInFormat. SIWE
+// messages are plain text signed with personal_sign like the legacy format.
+func (c *Client) signSignInMessage(signer wallet.Signer, message string) (string, error) {
+	switch c.SignInFormat {
+	case SignInEIP712:
+		data, err := parseTypedData(message)
+		if err != nil {
+			return "", err
+		}
+		return signer.SignTypedData(data)
+	default:
+		return signer.SignMessage(message)
+	}
+}
+
+func (c *Client) SignInMessage(walletAddress string) (string, error) {
+	url := fmt.Sprintf("%s/auths/wallets/sign-in/message?address=%s&type=ETHEREUM_BASED", c.BaseURL, walletAddress)
+	resp, err := c.HTTPClient.Get(url)
 	if err != nil {
 		return "", fmt.Errorf("HTTP request failed: %v", err)
 	}
@@ -64,7 +92,7 @@ func getSignMessage(walletAddress string) (string, error) {
 	return response.Data.Message, nil
 }
 
-func authenticate(walletAddress, message, signature string) (string, error) {
+func (c *Client) authenticate(walletAddress, message, signature string) (string, error) {
     authReq := map[string]string{
         "address":   walletAddress,
         "message":   message,
@@ -77,8 +105,8 @@ func authenticate(walletAddress, message, signature string) (string, error) {
         return "", fmt.Errorf("failed to marshal request: %v", err)
     }
 
-    resp, err := http.Post(
-        fmt.Sprintf("%s/auths/wallets/sign-in", baseURL),
+    resp, err := c.HTTPClient.Post(
+        fmt.Sprintf("%s/auths/wallets/sign-in", c.BaseURL),
         "application/json",
         bytes.NewBuffer(jsonBody),
     )
@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/chaintest"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+const testPrivateKey = chaintest.TestPrivateKey
+
+type badSigner struct {
+	*wallet.Wallet
+}
+
+func (b badSigner) SignMessage(message string) (string, error) {
+	return b.Wallet.SignMessage(message + " tampered")
+}
+
+func TestWalletSignInAgainstMockServer(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	token, err := server.Client().WalletSignIn(w)
+	if err != nil {
+		t.Fatalf("WalletSignIn: %v", err)
+	}
+	if token == "" {
+		t.Fatal("WalletSignIn returned empty token")
+	}
+}
+
+func TestWalletSignInRejectsWrongSignature(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	if _, err := server.Client().WalletSignIn(badSigner{w}); err == nil {
+		t.Fatal("WalletSignIn succeeded with a signature over the wrong message")
+	}
+}
+
+func TestWalletSignInInjectedFailure(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	server.Fail(apitest.RouteSignInMessage, 1, http.StatusServiceUnavailable, map[string]string{"message": "down"})
+
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	if _, err := server.Client().WalletSignIn(w); err == nil {
+		t.Fatal("WalletSignIn succeeded despite injected failure")
+	}
+	if _, err := server.Client().WalletSignIn(w); err != nil {
+		t.Fatalf("WalletSignIn after failure drained: %v", err)
+	}
+}
+
+func TestWalletSignInFormats(t *testing.T) {
+	for _, format := range []api.SignInFormat{api.SignInPersonal, api.SignInEIP712, api.SignInSIWE} {
+		t.Run(string(format), func(t *testing.T) {
+			server := apitest.New()
+			server.SignInFormat = format
+			server.ChainID = 1337
+			server.Start()
+			defer server.Close()
+
+			w, err := wallet.NewWallet(testPrivateKey)
+			if err != nil {
+				t.Fatalf("NewWallet: %v", err)
+			}
+			if _, err := server.Client().WalletSignIn(w); err != nil {
+				t.Fatalf("WalletSignIn: %v", err)
+			}
+
+			client := server.Client()
+			client.SignInFormat = api.SignInPersonal
+			if format == api.SignInEIP712 {
+				if _, err := client.WalletSignIn(w); err == nil {
+					t.Fatal("personal_sign signature accepted for an EIP-712 sign-in")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/chaintest"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	server := apitest.NewServer()
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	recorder := api.NewClientWithOptions(server.URL, api.Options{Transport: api.NewRecordingTransport(nil, path)})
+	token, err := recorder.WalletSignIn(w)
+	if err != nil {
+		t.Fatalf("WalletSignIn: %v", err)
+	}
+	order, err := recorder.CreateVoteOrder(token, "678dbb6579af53b8da5ddf3d", "10143", "VN", "http://rpc", "wallet", 2)
+	if err != nil {
+		t.Fatalf("CreateVoteOrder: %v", err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), token) {
+		t.Fatalf("cassette contains unredacted token %q", token)
+	}
+	if !strings.Contains(string(data), `\"signature\":\"[REDACTED]\"`) {
+		t.Fatal("cassette does not redact the sign-in signature")
+	}
+	if !strings.Contains(string(data), strings.TrimPrefix(order.Data.Payment.Params.IntegritySignature, "0x")) {
+		t.Fatal("cassette redacted the public integritySignature")
+	}
+
+	cassette, err := api.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	if len(cassette.Interactions) != 3 {
+		t.Fatalf("recorded %d interactions, want 3", len(cassette.Interactions))
+	}
+
+	replay := api.NewReplayTransport(cassette)
+	client := api.NewClientWithOptions(server.URL, api.Options{Transport: replay})
+	replayed, err := client.CreateVoteOrder("any", "678dbb6579af53b8da5ddf3d", "10143", "VN", "http://rpc", "wallet", 2)
+	if err != nil {
+		t.Fatalf("replayed CreateVoteOrder: %v", err)
+	}
+	if replayed.Data.Order.ID != order.Data.Order.ID {
+		t.Fatalf("replayed order ID = %s, want %s", replayed.Data.Order.ID, order.Data.Order.ID)
+	}
+	if replay.Remaining() != 2 {
+		t.Fatalf("remaining interactions = %d, want 2", replay.Remaining())
+	}
+
+	if _, err := client.GetVoteOrder("any", order.Data.Order.ID); err == nil {
+		t.Fatal("GetVoteOrder succeeded without a recorded interaction")
+	}
+}
+
+func TestRecordAndReplayVote(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	job := pipeline.VoteJob{
+		RPCURL:          "simulated://chain",
+		ChainID:         chaintest.ChainID,
+		WalletID:        "test-wallet",
+		CandidateID:     "678dbb6579af53b8da5ddf3d",
+		TargetCountryID: "VN",
+		FeedAmount:      2,
+	}
+
+	env := chaintest.NewEnv(t)
+	recorder := api.NewClientWithOptions(env.Server.URL, api.Options{Transport: api.NewRecordingTransport(nil, path)})
+	recorded, err := pipeline.NewRunner(env.Wallet, recorder).Run(context.Background(), job)
+	if err != nil {
+		t.Fatalf("recorded Run: %v", err)
+	}
+	env.Server.Close()
+
+	cassette, err := api.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	replayEnv := chaintest.NewEnv(t)
+	replay := api.NewReplayTransport(cassette)
+	client := api.NewClientWithOptions(env.Server.URL, api.Options{Transport: replay})
+	replayed, err := pipeline.NewRunner(replayEnv.Wallet, client).Run(context.Background(), job)
+	if err != nil {
+		t.Fatalf("replayed Run: %v", err)
+	}
+	if replayed.Status != pipeline.StatusConfirmed || replayed.OrderID != recorded.OrderID {
+		t.Fatalf("replayed = %+v, want order %s confirmed", replayed, recorded.OrderID)
+	}
+	if replay.Remaining() != 0 {
+		t.Fatalf("remaining interactions = %d, want 0", replay.Remaining())
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://api.aicraft.fun"
+)
+
+// SignInFormat selects how the sign-in message from the API is signed.
+type SignInFormat string
+
+const (
+	SignInPersonal SignInFormat = "personal_sign"
+	SignInEIP712   SignInFormat = "eip712"
+	SignInSIWE     SignInFormat = "siwe"
+)
+
+type Client struct {
+	BaseURL      string
+	HTTPClient   *http.Client
+	SignInFormat SignInFormat
+	// SignInDomain is the site SIWE messages must name, usually the web app
+	// origin; empty expects the API host.
+	SignInDomain string
+	// ChainID is the chain sign-in messages must name; 0 accepts any.
+	ChainID int64
+}
+
+type Options struct {
+	RequestsPerSecond float64
+	Burst             int
+	MaxRetries        int
+	Transport         http.RoundTripper
+	Observe           RequestObserver
+	SignInFormat      SignInFormat
+	SignInDomain      string
+	ChainID           int64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		RequestsPerSecond: DefaultRequestsPerSecond,
+		Burst:             DefaultBurst,
+		MaxRetries:        DefaultMaxRetries,
+	}
+}
+
+func NewClient(baseURL string) *Client {
+	return NewClientWithOptions(baseURL, DefaultOptions())
+}
+
+func NewClientWithOptions(baseURL string, opts Options) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	transport := opts.Transport
+	if opts.Observe != nil {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		transport = &observeTransport{base: transport, observe: opts.Observe}
+	}
+	return &Client{
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+		SignInFormat: opts.SignInFormat,
+		SignInDomain: opts.SignInDomain,
+		ChainID:      opts.ChainID,
+		HTTPClient: &http.Client{
+			Timeout:   60 * time.Second,
+			Transport: NewRateLimitTransport(transport, opts.RequestsPerSecond, opts.Burst, opts.MaxRetries),
+		},
+	}
+}
+
+// Ping reports whether the API answers at all, without touching any sign-in
+// state. Any response below 500 counts as an answer.
+func (c *Client) Ping() error {
+	resp, err := c.HTTPClient.Get(c.BaseURL + "/")
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
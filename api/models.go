@@ -52,6 +52,11 @@ type ConfirmResponse struct {
 	} `json:"data"`
 }
 
+const (
+	OrderStatusPending   = "PENDING"
+	OrderStatusConfirmed = "CONFIRMED"
+)
+
 type OrderResponse struct {
 	StatusCode int    `json:"statusCode"`
 	Time       string `json:"time"`
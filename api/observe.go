@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	EndpointSignInMessage = "sign-in-message"
+	EndpointSignIn        = "sign-in"
+	EndpointCreateOrder   = "create-order"
+	EndpointGetOrder      = "get-order"
+	EndpointConfirmOrder  = "confirm-order"
+	EndpointOther         = "other"
+)
+
+// RequestObserver is told about every HTTP exchange with the API. status is
+// zero when the request failed before a response arrived.
+type RequestObserver func(endpoint string, status int, elapsed time.Duration, err error)
+
+type observeTransport struct {
+	base    http.RoundTripper
+	observe RequestObserver
+}
+
+func (t *observeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	t.observe(Endpoint(req.Method, req.URL.Path), status, time.Since(start), err)
+	return resp, err
+}
+
+// Endpoint names the API call a request belongs to, collapsing order IDs so
+// the result is usable as a metric label.
+func Endpoint(method, path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "auths" && parts[3] == "message":
+		return EndpointSignInMessage
+	case len(parts) == 3 && parts[0] == "auths" && parts[2] == "sign-in":
+		return EndpointSignIn
+	case len(parts) == 2 && parts[0] == "feeds" && parts[1] == "orders" && method == http.MethodPost:
+		return EndpointCreateOrder
+	case len(parts) == 3 && parts[0] == "feeds" && parts[1] == "orders" && method == http.MethodGet:
+		return EndpointGetOrder
+	case len(parts) == 4 && parts[0] == "feeds" && parts[3] == "confirm":
+		return EndpointConfirmOrder
+	}
+	return EndpointOther
+}
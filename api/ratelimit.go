@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultRequestsPerSecond = 5
+	DefaultBurst             = 5
+	DefaultMaxRetries        = 3
+
+	maxBackoff = 10 * time.Second
+)
+
+// RateLimitTransport applies a token bucket per host and retries requests
+// rejected with 429 Too Many Requests, honoring Retry-After when present.
+type RateLimitTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+
+	limit rate.Limit
+	burst int
+
+	mu          sync.Mutex
+	limiters    map[string]*rate.Limiter
+	pausedUntil map[string]time.Time
+}
+
+func NewRateLimitTransport(base http.RoundTripper, requestsPerSecond float64, burst, maxRetries int) *RateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limit := rate.Inf
+	if requestsPerSecond > 0 {
+		limit = rate.Limit(requestsPerSecond)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitTransport{
+		Base:        base,
+		MaxRetries:  maxRetries,
+		limit:       limit,
+		burst:       burst,
+		limiters:    make(map[string]*rate.Limiter),
+		pausedUntil: make(map[string]time.Time),
+	}
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(req, host); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.Base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= t.MaxRetries {
+			return resp, nil
+		}
+
+		delay := retryAfter(resp.Header.Get("Retry-After"), attempt)
+		slog.Warn("API rate limited, backing off", "host", host, "path", req.URL.Path, "attempt", attempt+1, "delay", delay)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		t.pause(host, delay)
+
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("rate limited and request body cannot be replayed")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %v", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func (t *RateLimitTransport) wait(req *http.Request, host string) error {
+	t.mu.Lock()
+	limiter, ok := t.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(t.limit, t.burst)
+		t.limiters[host] = limiter
+	}
+	until := t.pausedUntil[host]
+	t.mu.Unlock()
+
+	if d := time.Until(until); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-req.Context().Done():
+			return req.Context().Err()
+		}
+	}
+	return limiter.Wait(req.Context())
+}
+
+func (t *RateLimitTransport) pause(host string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	until := time.Now().Add(d)
+	if until.After(t.pausedUntil[host]) {
+		t.pausedUntil[host] = until
+	}
+}
+
+func retryAfter(header string, attempt int) time.Duration {
+	if header != "" {
+		if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+			return capBackoff(time.Duration(seconds) * time.Second)
+		}
+		if at, err := http.ParseTime(header); err == nil {
+			return capBackoff(time.Until(at))
+		}
+	}
+	return capBackoff(time.Second << attempt)
+}
+
+func capBackoff(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
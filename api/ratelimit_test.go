@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+func TestClientRetriesAfterTooManyRequests(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	server.Inject(apitest.RouteSignInMessage, 2, apitest.Response{
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"0"}},
+		Body:   map[string]string{"message": "slow down"},
+	})
+
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	if _, err := server.Client().WalletSignIn(w); err != nil {
+		t.Fatalf("WalletSignIn: %v", err)
+	}
+	if got := server.Requests(apitest.RouteSignInMessage); got != 3 {
+		t.Fatalf("sign-in message requests = %d, want 3", got)
+	}
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	server.Inject(apitest.RouteSignInMessage, 5, apitest.Response{
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"0"}},
+	})
+
+	client := api.NewClientWithOptions(server.URL, api.Options{RequestsPerSecond: 100, Burst: 1, MaxRetries: 1})
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	if _, err := client.WalletSignIn(w); err == nil {
+		t.Fatal("WalletSignIn succeeded despite persistent 429s")
+	}
+	if got := server.Requests(apitest.RouteSignInMessage); got != 2 {
+		t.Fatalf("sign-in message requests = %d, want 2", got)
+	}
+}
+
+func TestClientLimitsRequestRate(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+
+	client := api.NewClientWithOptions(server.URL, api.Options{RequestsPerSecond: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		client.GetVoteOrder("token", "missing")
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("5 requests at 20 rps with burst 1 took %v, want at least 150ms", elapsed)
+	}
+}
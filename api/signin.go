@@ -0,0 +1,339 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var ErrUnexpectedSignInMessage = errors.New("refusing to sign unexpected sign-in message")
+
+var (
+	// hexBlobPattern matches hex longer than an address, such as calldata or
+	// a raw transaction smuggled into a message.
+	hexBlobPattern = regexp.MustCompile(`0x[0-9a-fA-F]{41,}`)
+	addressPattern = regexp.MustCompile(`0x[0-9a-fA-F]{40}\b`)
+	// issuedAtPattern and expiresPattern find the timestamps a plain-text
+	// message may carry, e.g. "Issued At: 2026-03-01T12:00:00Z".
+	issuedAtPattern = regexp.MustCompile(`(?im)^\s*(?:issued[ _-]?at|timestamp)\s*[:=]\s*(\S+)\s*$`)
+	expiresPattern  = regexp.MustCompile(`(?im)^\s*(?:expiration[ _-]?time|expires(?:[ _-]?at)?)\s*[:=]\s*(\S+)\s*$`)
+)
+
+const (
+	// SignInMaxAge bounds how long ago a sign-in message may have been issued.
+	SignInMaxAge = 10 * time.Minute
+	// SignInClockSkew is how far in the future a message may claim to be issued.
+	SignInClockSkew = time.Minute
+
+	siweHeader = " wants you to sign in with your Ethereum account:"
+)
+
+// SignInExpectations are what a sign-in message must agree with before it
+// is signed. Domain is the site SIWE messages must name and a zero ChainID
+// skips the chain check.
+type SignInExpectations struct {
+	Domain  string
+	Address string
+	ChainID int64
+	Now     time.Time
+}
+
+// SIWEMessage is an EIP-4361 Sign-In with Ethereum message.
+type SIWEMessage struct {
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// ParseSIWE parses message strictly as EIP-4361, rejecting unknown fields.
+func ParseSIWE(message string) (*SIWEMessage, error) {
+	lines := strings.Split(message, "\n")
+	if len(lines) < 3 || !strings.HasSuffix(lines[0], siweHeader) {
+		return nil, errors.New("missing SIWE header")
+	}
+	m := &SIWEMessage{
+		Domain:  strings.TrimSuffix(lines[0], siweHeader),
+		Address: lines[1],
+	}
+	if m.Domain == "" || !common.IsHexAddress(m.Address) {
+		return nil, errors.New("SIWE header needs a domain and an address")
+	}
+	if lines[2] != "" {
+		return nil, errors.New("expected a blank line after the address")
+	}
+
+	rest := lines[3:]
+	if len(rest) >= 2 && !strings.Contains(rest[0], ": ") && rest[1] == "" {
+		m.Statement = rest[0]
+		rest = rest[2:]
+		if hexBlobPattern.MatchString(m.Statement) || !printable(m.Statement) {
+			return nil, errors.New("statement carries binary or hex data")
+		}
+	} else if len(rest) > 0 && rest[0] == "" {
+		rest = rest[1:]
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == "Resources:" {
+			for i+1 < len(rest) && strings.HasPrefix(rest[i+1], "- ") {
+				i++
+				m.Resources = append(m.Resources, strings.TrimPrefix(rest[i], "- "))
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(rest[i], ": ")
+		if !ok || seen[key] {
+			return nil, fmt.Errorf("unexpected line %q", rest[i])
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "URI":
+			m.URI = value
+		case "Version":
+			m.Version = value
+		case "Chain ID":
+			m.ChainID, err = strconv.ParseInt(value, 10, 64)
+		case "Nonce":
+			m.Nonce = value
+		case "Issued At":
+			m.IssuedAt, err = time.Parse(time.RFC3339, value)
+		case "Expiration Time":
+			m.ExpirationTime, err = time.Parse(time.RFC3339, value)
+		case "Not Before":
+			m.NotBefore, err = time.Parse(time.RFC3339, value)
+		case "Request ID":
+			m.RequestID = value
+		default:
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", key, value, err)
+		}
+	}
+
+	for _, key := range []string{"URI", "Version", "Chain ID", "Nonce", "Issued At"} {
+		if !seen[key] {
+			return nil, fmt.Errorf("missing %s", key)
+		}
+	}
+	if m.Version != "1" {
+		return nil, fmt.Errorf("unsupported version %q", m.Version)
+	}
+	return m, nil
+}
+
+// Check verifies m against want.
+func (m *SIWEMessage) Check(want SignInExpectations) error {
+	if !strings.EqualFold(m.Domain, want.Domain) {
+		return fmt.Errorf("domain is %s, expected %s", m.Domain, want.Domain)
+	}
+	if u, err := url.Parse(m.URI); err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Host, want.Domain) {
+		return fmt.Errorf("URI %s is not on %s", m.URI, want.Domain)
+	}
+	if err := checkAddress(m.Address, want.Address); err != nil {
+		return err
+	}
+	if want.ChainID != 0 && m.ChainID != want.ChainID {
+		return fmt.Errorf("chain ID is %d, expected %d", m.ChainID, want.ChainID)
+	}
+	if len(m.Nonce) < 8 {
+		return fmt.Errorf("nonce %q is too short", m.Nonce)
+	}
+	if err := checkIssuedAt(m.IssuedAt, want.Now); err != nil {
+		return err
+	}
+	if !m.ExpirationTime.IsZero() && !want.Now.Before(m.ExpirationTime) {
+		return fmt.Errorf("message expired at %s", m.ExpirationTime.Format(time.RFC3339))
+	}
+	if !m.NotBefore.IsZero() && want.Now.Before(m.NotBefore) {
+		return fmt.Errorf("message is not valid before %s", m.NotBefore.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// CheckSignInMessage decides whether message is safe to sign in the given
+// format. personal_sign accepts SIWE or any text passing checkTextSignIn,
+// siwe only SIWE, and eip712 typed data whose domain and addresses point at
+// us.
+func CheckSignInMessage(format SignInFormat, message string, want SignInExpectations) error {
+	if want.Now.IsZero() {
+		want.Now = time.Now()
+	}
+	var err error
+	switch {
+	case format == SignInEIP712:
+		err = checkTypedSignIn(message, want)
+	case format == SignInSIWE || strings.Contains(message, siweHeader):
+		var m *SIWEMessage
+		if m, err = ParseSIWE(message); err == nil {
+			err = m.Check(want)
+		}
+	default:
+		err = checkTextSignIn(message, want)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnexpectedSignInMessage, err)
+	}
+	return nil
+}
+
+// checkTextSignIn applies general rules to a plain-text message whose exact
+// layout is not known: it must be printable, carry no hex data longer than
+// an address, any address it names must be ours, and any issued-at or expiry
+// time it states must hold now.
+func checkTextSignIn(message string, want SignInExpectations) error {
+	if !printable(message) {
+		return errors.New("message contains binary data")
+	}
+	if blob := hexBlobPattern.FindString(message); blob != "" {
+		return fmt.Errorf("message carries hex data %.20s...", blob)
+	}
+	for _, address := range addressPattern.FindAllString(message, -1) {
+		if err := checkAddress(address, want.Address); err != nil {
+			return err
+		}
+	}
+	var issued, expires string
+	if m := issuedAtPattern.FindStringSubmatch(message); m != nil {
+		issued = m[1]
+	}
+	if m := expiresPattern.FindStringSubmatch(message); m != nil {
+		expires = m[1]
+	}
+	return checkTimes(issued, expires, want.Now)
+}
+
+// checkTimes enforces the issued-at and expiry times a message states, as
+// RFC 3339 or Unix seconds or milliseconds. Empty values are not checked.
+func checkTimes(issued, expires string, now time.Time) error {
+	if issued != "" {
+		at, err := parseSignInTime(issued)
+		if err != nil {
+			return fmt.Errorf("invalid issued-at time %q", issued)
+		}
+		if err := checkIssuedAt(at, now); err != nil {
+			return err
+		}
+	}
+	if expires != "" {
+		at, err := parseSignInTime(expires)
+		if err != nil {
+			return fmt.Errorf("invalid expiration time %q", expires)
+		}
+		if !now.Before(at) {
+			return fmt.Errorf("message expired at %s", at.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+func parseSignInTime(value string) (time.Time, error) {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// checkTypedSignIn refuses typed data bound to a contract or naming another
+// address, which is how permits and orders that move funds look. A sign-in
+// only needs string and address fields, so any other type is refused.
+func checkTypedSignIn(message string, want SignInExpectations) error {
+	data, err := parseTypedData(message)
+	if err != nil {
+		return err
+	}
+	if data.Domain.VerifyingContract != "" {
+		return fmt.Errorf("domain names verifying contract %s", data.Domain.VerifyingContract)
+	}
+	if want.ChainID != 0 && data.Domain.ChainId != nil && (*big.Int)(data.Domain.ChainId).Int64() != want.ChainID {
+		return fmt.Errorf("domain chain ID is %s, expected %d", (*big.Int)(data.Domain.ChainId), want.ChainID)
+	}
+
+	var mentionsUs bool
+	var issued, expires string
+	for _, field := range data.Types[data.PrimaryType] {
+		value, _ := data.Message[field.Name].(string)
+		switch field.Type {
+		case "address":
+			if err := checkAddress(value, want.Address); err != nil {
+				return fmt.Errorf("%s: %v", field.Name, err)
+			}
+			mentionsUs = true
+		case "string":
+			switch strings.ToLower(field.Name) {
+			case "issuedat", "timestamp":
+				issued = value
+			case "expirationtime", "expiresat", "expires":
+				expires = value
+			}
+		default:
+			return fmt.Errorf("%s has type %s, which a sign-in does not need", field.Name, field.Type)
+		}
+	}
+	if !mentionsUs {
+		return errors.New("typed data does not name the signing address")
+	}
+	return checkTimes(issued, expires, want.Now)
+}
+
+func parseTypedData(message string) (apitypes.TypedData, error) {
+	var data apitypes.TypedData
+	if err := json.Unmarshal([]byte(message), &data); err != nil {
+		return data, fmt.Errorf("sign-in message is not EIP-712 typed data: %v", err)
+	}
+	return data, nil
+}
+
+func checkAddress(got, want string) error {
+	if !common.IsHexAddress(got) {
+		return fmt.Errorf("address %q is not valid", got)
+	}
+	if common.HexToAddress(got) != common.HexToAddress(want) {
+		return fmt.Errorf("address is %s, expected %s", got, want)
+	}
+	return nil
+}
+
+func checkIssuedAt(issued, now time.Time) error {
+	if issued.After(now.Add(SignInClockSkew)) {
+		return fmt.Errorf("issued in the future at %s", issued.Format(time.RFC3339))
+	}
+	if now.Sub(issued) > SignInMaxAge {
+		return fmt.Errorf("issued too long ago at %s", issued.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func printable(s string) bool {
+	for _, r := range s {
+		if r != '\n' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+const signInAddress = "0x90F8bf6A479f320ead074411a4B0e7944Ea8c9C1"
+
+var signInNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func siweMessage(domain, address string, chainID int64, issued time.Time, extra string) string {
+	return fmt.Sprintf("%s wants you to sign in with your Ethereum account:\n%s\n\nSign in to AICraft.\n\nURI: https://%s\nVersion: 1\nChain ID: %d\nNonce: 32891756\nIssued At: %s\nExpiration Time: %s%s",
+		domain, address, domain, chainID, issued.Format(time.RFC3339), issued.Add(10*time.Minute).Format(time.RFC3339), extra)
+}
+
+func TestCheckSignInMessage(t *testing.T) {
+	want := api.SignInExpectations{Domain: "api.aicraft.fun", Address: signInAddress, ChainID: 10143, Now: signInNow}
+	welcome := "Welcome to AICraft!\n\nSign this message to authenticate.\n\nWallet: " + signInAddress + "\nNonce: 5f2b"
+	typed := `{"types":{"SignIn":[{"name":"wallet","type":"address"},{"name":"nonce","type":"string"}]},"primaryType":"SignIn","domain":{"name":"AICraft","version":"1","chainId":10143},"message":{"wallet":"` + signInAddress + `","nonce":"5f2b"}}`
+	permit := `{"types":{"Permit":[{"name":"owner","type":"address"},{"name":"spender","type":"address"},{"name":"value","type":"uint256"}]},"primaryType":"Permit","domain":{"name":"Token","chainId":10143,"verifyingContract":"0x0000000000000000000000000000000000000AC1"},"message":{"owner":"` + signInAddress + `","spender":"0x000000000000000000000000000000000000dEaD","value":"1"}}`
+
+	typedIssuedAt := func(at time.Time) string {
+		data := strings.Replace(typed, `{"name":"nonce","type":"string"}`, `{"name":"nonce","type":"string"},{"name":"issuedAt","type":"string"}`, 1)
+		return strings.Replace(data, `"nonce":"5f2b"`, `"nonce":"5f2b","issuedAt":"`+at.Format(time.RFC3339)+`"`, 1)
+	}
+
+	tests := []struct {
+		name    string
+		format  api.SignInFormat
+		message string
+		ok      bool
+	}{
+		{"siwe", api.SignInSIWE, siweMessage("api.aicraft.fun", signInAddress, 10143, signInNow.Add(-time.Minute), ""), true},
+		{"siwe via personal_sign", api.SignInPersonal, siweMessage("api.aicraft.fun", signInAddress, 10143, signInNow, ""), true},
+		{"siwe wrong domain", api.SignInSIWE, siweMessage("evil.example", signInAddress, 10143, signInNow, ""), false},
+		{"siwe wrong address", api.SignInSIWE, siweMessage("api.aicraft.fun", "0x000000000000000000000000000000000000dEaD", 10143, signInNow, ""), false},
+		{"siwe wrong chain", api.SignInSIWE, siweMessage("api.aicraft.fun", signInAddress, 1, signInNow, ""), false},
+		{"siwe expired", api.SignInSIWE, siweMessage("api.aicraft.fun", signInAddress, 10143, signInNow.Add(-time.Hour), ""), false},
+		{"siwe issued in the future", api.SignInSIWE, siweMessage("api.aicraft.fun", signInAddress, 10143, signInNow.Add(time.Hour), ""), false},
+		{"siwe unknown field", api.SignInSIWE, siweMessage("api.aicraft.fun", signInAddress, 10143, signInNow, "\nAmount: 100"), false},
+		{"siwe format refuses welcome", api.SignInSIWE, welcome, false},
+		{"welcome", api.SignInPersonal, welcome, true},
+		{"welcome wrong wallet", api.SignInPersonal, strings.Replace(welcome, signInAddress, "0x000000000000000000000000000000000000dEaD", 1), false},
+		{"welcome with calldata", api.SignInPersonal, welcome + "\n0x" + strings.Repeat("ab", 68), false},
+		{"welcome with binary", api.SignInPersonal, welcome + "\x02\xf8", false},
+		{"unknown layout", api.SignInPersonal, "Sign in to AICraft\nnonce=5f2b&ts=1772366400", true},
+		{"unknown layout naming us", api.SignInPersonal, "Login " + signInAddress + " 5f2b", true},
+		{"welcome issued now", api.SignInPersonal, welcome + "\nIssued At: " + signInNow.Format(time.RFC3339), true},
+		{"welcome issued long ago", api.SignInPersonal, welcome + "\nIssued At: " + signInNow.Add(-time.Hour).Format(time.RFC3339), false},
+		{"welcome unix timestamp", api.SignInPersonal, welcome + fmt.Sprintf("\nTimestamp: %d", signInNow.Add(-time.Hour).UnixMilli()), false},
+		{"welcome expired", api.SignInPersonal, welcome + "\nExpiration Time: " + signInNow.Add(-time.Second).Format(time.RFC3339), false},
+		{"welcome unreadable time", api.SignInPersonal, welcome + "\nIssued At: yesterday", false},
+		{"text naming another address", api.SignInPersonal, "Transfer 100 MON to 0x000000000000000000000000000000000000dEaD", false},
+		{"typed data", api.SignInEIP712, typed, true},
+		{"typed data wrong chain", api.SignInEIP712, strings.Replace(typed, "10143", "1", 1), false},
+		{"typed data permit", api.SignInEIP712, permit, false},
+		{"typed data uint128", api.SignInEIP712, strings.Replace(typed, `"type":"string"`, `"type":"uint128"`, 1), false},
+		{"typed data array", api.SignInEIP712, strings.Replace(typed, `"type":"string"`, `"type":"string[]"`, 1), false},
+		{"typed data nested struct", api.SignInEIP712, strings.Replace(typed, `"type":"string"`, `"type":"Transfer"`, 1), false},
+		{"typed data issued now", api.SignInEIP712, typedIssuedAt(signInNow), true},
+		{"typed data issued long ago", api.SignInEIP712, typedIssuedAt(signInNow.Add(-time.Hour)), false},
+		{"typed data not json", api.SignInEIP712, welcome, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := api.CheckSignInMessage(tt.format, tt.message, want)
+			if tt.ok && err != nil {
+				t.Fatalf("CheckSignInMessage: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, api.ErrUnexpectedSignInMessage) {
+				t.Fatalf("CheckSignInMessage = %v, want %v", err, api.ErrUnexpectedSignInMessage)
+			}
+		})
+	}
+}
+
+func TestWalletSignInRefusesMessageForOtherChain(t *testing.T) {
+	server := apitest.New()
+	server.SignInFormat = api.SignInSIWE
+	server.ChainID = 1
+	server.Start()
+	defer server.Close()
+
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	client := server.Client()
+	client.ChainID = 10143
+
+	if _, err := client.WalletSignIn(w); !errors.Is(err, api.ErrUnexpectedSignInMessage) {
+		t.Fatalf("WalletSignIn = %v, want %v", err, api.ErrUnexpectedSignInMessage)
+	}
+	if n := server.Requests(apitest.RouteSignIn); n != 0 {
+		t.Fatalf("sign-in requests = %d, want none after refusing the message", n)
+	}
+}
+
+func TestWalletSignInChecksConfiguredDomain(t *testing.T) {
+	server := apitest.New()
+	server.SignInFormat = api.SignInSIWE
+	server.SignInDomain = "aicraft.fun"
+	server.Start()
+	defer server.Close()
+
+	w, err := wallet.NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	client := server.Client()
+	if _, err := client.WalletSignIn(w); !errors.Is(err, api.ErrUnexpectedSignInMessage) {
+		t.Fatalf("WalletSignIn against the API host = %v, want %v", err, api.ErrUnexpectedSignInMessage)
+	}
+
+	client.SignInDomain = "aicraft.fun"
+	if _, err := client.WalletSignIn(w); err != nil {
+		t.Fatalf("WalletSignIn: %v", err)
+	}
+}
@@ -5,15 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 )
 
-const (
-	baseURL1 = "https://api.aicraft.fun"
-)
-
-func CreateVoteOrder(token, candidateID, chainID, countryID, rpcURL, walletID string, feedAmount int) (*OrderResponse, error) {
-	url := fmt.Sprintf("%s/feeds/orders", baseURL)
+func (c *Client) CreateVoteOrder(token, candidateID, chainID, countryID, rpcURL, walletID string, feedAmount int) (*OrderResponse, error) {
+	url := fmt.Sprintf("%s/feeds/orders", c.BaseURL)
 
 	reqBody := map[string]interface{}{
 		"candidateID": candidateID,
@@ -37,15 +34,15 @@ func CreateVoteOrder(token, candidateID, chainID, countryID, rpcURL, walletID st
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	slog.Debug("creating vote order", "candidate_id", candidateID, "chain_id", chainID, "country_id", countryID, "feed_amount", feedAmount)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	fmt.Printf("Create order response: %s\n", string(body))
+	slog.Debug("create order response", "status", resp.StatusCode, "body", string(body))
 
 	if resp.StatusCode != http.StatusCreated {
 		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
@@ -63,8 +60,8 @@ func CreateVoteOrder(token, candidateID, chainID, countryID, rpcURL, walletID st
 	return &response, nil
 }
 
-func GetVoteOrder(token, orderID string) (*OrderResponse, error) {
-	url := fmt.Sprintf("%s/feeds/orders/%s", baseURL, orderID)
+func (c *Client) GetVoteOrder(token, orderID string) (*OrderResponse, error) {
+	url := fmt.Sprintf("%s/feeds/orders/%s", c.BaseURL, orderID)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -73,15 +70,14 @@ func GetVoteOrder(token, orderID string) (*OrderResponse, error) {
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	fmt.Printf("Get order response: %s\n", string(body))
+	slog.Debug("get order response", "order_id", orderID, "status", resp.StatusCode, "body", string(body))
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
@@ -95,8 +91,8 @@ func GetVoteOrder(token, orderID string) (*OrderResponse, error) {
 	return &response, nil
 }
 
-func ConfirmVoteOrder(token, orderID, txHash string) error {
-	url := fmt.Sprintf("%s/feeds/orders/%s/confirm", baseURL, orderID)
+func (c *Client) ConfirmVoteOrder(token, orderID, txHash string) error {
+	url := fmt.Sprintf("%s/feeds/orders/%s/confirm", c.BaseURL, orderID)
 
 	reqBody := map[string]interface{}{
 		"txHash": txHash,
@@ -115,15 +111,14 @@ func ConfirmVoteOrder(token, orderID, txHash string) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	fmt.Printf("Confirm order response: %s\n", string(body))
+	slog.Debug("confirm order response", "order_id", orderID, "status", resp.StatusCode, "body", string(body))
 
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
@@ -0,0 +1,564 @@
+package apitest
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+type Route string
+
+const (
+	RouteSignInMessage Route = "sign-in-message"
+	RouteSignIn        Route = "sign-in"
+	RouteCreateOrder   Route = "create-order"
+	RouteGetOrder      Route = "get-order"
+	RouteConfirmOrder  Route = "confirm-order"
+)
+
+const (
+	OrderStatusPending   = api.OrderStatusPending
+	OrderStatusConfirmed = api.OrderStatusConfirmed
+
+	DefaultContractAddress = "0x0000000000000000000000000000000000000AC1"
+	DefaultFunctionName    = "feed"
+)
+
+const feedABI = `[{"inputs":[{"name":"candidateID","type":"string","internalType":"string"},{"name":"feedAmount","type":"uint256","internalType":"uint256"},{"name":"requestID","type":"string","internalType":"string"},{"name":"requestData","type":"string","internalType":"string"},{"name":"userHashedMessage","type":"bytes","internalType":"bytes"},{"name":"integritySignature","type":"bytes","internalType":"bytes"}],"name":"feed","outputs":[],"stateMutability":"payable","type":"function"}]`
+
+// Payment is the template for an order's payment. An empty
+// UserHashedMessage is filled in with the hash of each order's requestData.
+type Payment struct {
+	ContractAddress    string
+	FunctionName       string
+	UserHashedMessage  string
+	IntegritySignature string
+}
+
+type Order struct {
+	ID          string
+	Address     string
+	CandidateID string
+	ChainID     string
+	CountryID   string
+	RPCURL      string
+	WalletID    string
+	FeedAmount  int
+	RequestData string
+	Status      string
+	TxHash      string
+	Payment     Payment
+}
+
+type Response struct {
+	Status int
+	Header http.Header
+	Body   interface{}
+}
+
+type Server struct {
+	URL string
+
+	// Payment is the template used for newly created orders.
+	Payment Payment
+	// SignInFormat selects the kind of sign-in message issued, and ChainID
+	// is the chain named in SIWE messages and EIP-712 domains.
+	SignInFormat api.SignInFormat
+	ChainID      int64
+	// SignInDomain is the site SIWE messages name; empty uses the request
+	// host.
+	SignInDomain string
+
+	// IntegrityKey, when set, replaces the template's userHashedMessage and
+	// integritySignature with values derived from each order as described by
+	// wallet.HashRequestData and wallet.IntegrityHash.
+	IntegrityKey *ecdsa.PrivateKey
+
+	mu        sync.Mutex
+	ts        *httptest.Server
+	messages  map[string]string
+	tokens    map[string]string
+	orders    map[string]*Order
+	requests  map[Route]int
+	failures  map[Route][]Response
+	responses map[Route]func(r *http.Request) *Response
+	delays    map[Route]time.Duration
+}
+
+func New() *Server {
+	return &Server{
+		Payment: Payment{
+			ContractAddress:    DefaultContractAddress,
+			FunctionName:       DefaultFunctionName,
+			IntegritySignature: "0x" + strings.Repeat("22", 65),
+		},
+		messages:  make(map[string]string),
+		tokens:    make(map[string]string),
+		orders:    make(map[string]*Order),
+		requests:  make(map[Route]int),
+		failures:  make(map[Route][]Response),
+		responses: make(map[Route]func(r *http.Request) *Response),
+		delays:    make(map[Route]time.Duration),
+	}
+}
+
+func NewServer() *Server {
+	s := New()
+	s.Start()
+	return s
+}
+
+func (s *Server) Start() {
+	s.ts = httptest.NewServer(s)
+	s.URL = s.ts.URL
+}
+
+func (s *Server) Close() {
+	if s.ts != nil {
+		s.ts.Close()
+	}
+}
+
+func (s *Server) Client() *api.Client {
+	c := api.NewClient(s.URL)
+	c.SignInFormat = s.SignInFormat
+	return c
+}
+
+// Fail makes the next n requests to route respond with status and body.
+func (s *Server) Fail(route Route, n int, status int, body interface{}) {
+	s.Inject(route, n, Response{Status: status, Body: body})
+}
+
+// Inject queues resp as the reply to the next n requests to route.
+func (s *Server) Inject(route Route, n int, resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures[route] = append(s.failures[route], resp)
+	}
+}
+
+// Respond overrides route with fn. Returning nil from fn falls through to the default behavior.
+func (s *Server) Respond(route Route, fn func(r *http.Request) *Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fn == nil {
+		delete(s.responses, route)
+		return
+	}
+	s.responses[route] = fn
+}
+
+func (s *Server) Delay(route Route, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delays[route] = d
+}
+
+func (s *Server) Requests(route Route) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[route]
+}
+
+func (s *Server) Order(id string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[id]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+func (s *Server) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make([]Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		orders = append(orders, *o)
+	}
+	return orders
+}
+
+// ConfirmOrder marks an order confirmed as if the confirm endpoint had been
+// called, e.g. to simulate a confirm whose response never arrived.
+func (s *Server) ConfirmOrder(id, txHash string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, ok := s.orders[id]
+	if ok {
+		order.Status = OrderStatusConfirmed
+		order.TxHash = txHash
+	}
+	return ok
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, orderID, ok := matchRoute(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "route not found")
+		return
+	}
+	slog.Debug("mock api request", "method", r.Method, "path", r.URL.Path, "route", route)
+
+	s.mu.Lock()
+	s.requests[route]++
+	delay := s.delays[route]
+	var injected *Response
+	if queue := s.failures[route]; len(queue) > 0 {
+		injected = &queue[0]
+		s.failures[route] = queue[1:]
+	}
+	custom := s.responses[route]
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if injected != nil {
+		writeResponse(w, injected)
+		return
+	}
+	if custom != nil {
+		if resp := custom(r); resp != nil {
+			writeResponse(w, resp)
+			return
+		}
+	}
+
+	switch route {
+	case RouteSignInMessage:
+		s.handleSignInMessage(w, r)
+	case RouteSignIn:
+		s.handleSignIn(w, r)
+	case RouteCreateOrder:
+		s.handleCreateOrder(w, r)
+	case RouteGetOrder:
+		s.handleGetOrder(w, r, orderID)
+	case RouteConfirmOrder:
+		s.handleConfirmOrder(w, r, orderID)
+	}
+}
+
+func matchRoute(r *http.Request) (Route, string, bool) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodGet && path == "/auths/wallets/sign-in/message":
+		return RouteSignInMessage, "", true
+	case r.Method == http.MethodPost && path == "/auths/wallets/sign-in":
+		return RouteSignIn, "", true
+	case r.Method == http.MethodPost && path == "/feeds/orders":
+		return RouteCreateOrder, "", true
+	case strings.HasPrefix(path, "/feeds/orders/"):
+		rest := strings.Split(strings.TrimPrefix(path, "/feeds/orders/"), "/")
+		if r.Method == http.MethodGet && len(rest) == 1 {
+			return RouteGetOrder, rest[0], true
+		}
+		if r.Method == http.MethodPost && len(rest) == 2 && rest[1] == "confirm" {
+			return RouteConfirmOrder, rest[0], true
+		}
+	}
+	return "", "", false
+}
+
+func (s *Server) handleSignInMessage(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "address is required")
+		return
+	}
+
+	message := s.signInMessage(r, address, randomHex(16), time.Now().UTC())
+
+	s.mu.Lock()
+	s.messages[strings.ToLower(address)] = message
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"statusCode": http.StatusOK,
+		"data":       map[string]string{"message": message},
+	})
+}
+
+func (s *Server) signInMessage(r *http.Request, address, nonce string, now time.Time) string {
+	switch s.SignInFormat {
+	case api.SignInEIP712:
+		data := apitypes.TypedData{
+			Types: apitypes.Types{
+				"SignIn": {
+					{Name: "wallet", Type: "address"},
+					{Name: "nonce", Type: "string"},
+					{Name: "issuedAt", Type: "string"},
+				},
+			},
+			PrimaryType: "SignIn",
+			Domain:      apitypes.TypedDataDomain{Name: "AICraft", Version: "1"},
+			Message: apitypes.TypedDataMessage{
+				"wallet":   address,
+				"nonce":    nonce,
+				"issuedAt": now.Format(time.RFC3339),
+			},
+		}
+		if s.ChainID != 0 {
+			chainID := math.HexOrDecimal256(*big.NewInt(s.ChainID))
+			data.Domain.ChainId = &chainID
+		}
+		message, _ := json.Marshal(data)
+		return string(message)
+	case api.SignInSIWE:
+		domain := s.SignInDomain
+		if domain == "" {
+			domain = r.Host
+		}
+		return fmt.Sprintf("%s wants you to sign in with your Ethereum account:\n%s\n\nSign in to AICraft.\n\nURI: http://%s\nVersion: 1\nChain ID: %d\nNonce: %s\nIssued At: %s\nExpiration Time: %s",
+			domain, address, domain, s.ChainID, nonce, now.Format(time.RFC3339), now.Add(10*time.Minute).Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("Welcome to AICraft!\n\nSign this message to authenticate.\n\nWallet: %s\nNonce: %s", address, nonce)
+	}
+}
+
+func (s *Server) verifySignIn(address, message, signature string) error {
+	if s.SignInFormat != api.SignInEIP712 {
+		return wallet.VerifySignature(address, message, signature)
+	}
+	var data apitypes.TypedData
+	if err := json.Unmarshal([]byte(message), &data); err != nil {
+		return err
+	}
+	return wallet.VerifyTypedData(address, data, signature)
+}
+
+func (s *Server) handleSignIn(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Address   string `json:"address"`
+		Message   string `json:"message"`
+		Signature string `json:"signature"`
+		Type      string `json:"type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	s.mu.Lock()
+	issued, ok := s.messages[strings.ToLower(req.Address)]
+	s.mu.Unlock()
+	if !ok || issued != req.Message {
+		writeError(w, http.StatusUnauthorized, "unknown sign-in message")
+		return
+	}
+	if err := s.verifySignIn(req.Address, req.Message, req.Signature); err != nil {
+		writeError(w, http.StatusUnauthorized, fmt.Sprintf("invalid signature: %v", err))
+		return
+	}
+
+	token := randomHex(32)
+	s.mu.Lock()
+	delete(s.messages, strings.ToLower(req.Address))
+	s.tokens[token] = req.Address
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"statusCode": http.StatusCreated,
+		"data": map[string]string{
+			"token":     token,
+			"expiresAt": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+func (s *Server) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
+	address, ok := s.authorize(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req struct {
+		CandidateID string `json:"candidateID"`
+		ChainID     string `json:"chainID"`
+		CountryID   string `json:"countryId"`
+		RPCURL      string `json:"rpcUrl"`
+		WalletID    string `json:"walletID"`
+		FeedAmount  int    `json:"feedAmount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.CandidateID == "" || req.FeedAmount <= 0 {
+		writeError(w, http.StatusBadRequest, "candidateID and feedAmount are required")
+		return
+	}
+
+	requestData, _ := json.Marshal(map[string]interface{}{
+		"candidateID": req.CandidateID,
+		"countryId":   req.CountryID,
+		"feedAmount":  req.FeedAmount,
+		"walletID":    req.WalletID,
+	})
+
+	s.mu.Lock()
+	order := &Order{
+		ID:          randomHex(12),
+		Address:     address,
+		CandidateID: req.CandidateID,
+		ChainID:     req.ChainID,
+		CountryID:   req.CountryID,
+		RPCURL:      req.RPCURL,
+		WalletID:    req.WalletID,
+		FeedAmount:  req.FeedAmount,
+		RequestData: string(requestData),
+		Status:      OrderStatusPending,
+		Payment:     s.Payment,
+	}
+	if order.Payment.UserHashedMessage == "" {
+		order.Payment.UserHashedMessage = wallet.HashRequestData(order.RequestData).Hex()
+	}
+	if s.IntegrityKey != nil {
+		order.Payment.UserHashedMessage, order.Payment.IntegritySignature = signIntegrity(s.IntegrityKey, order, common.HexToAddress(address))
+	}
+	s.orders[order.ID] = order
+	resp := orderResponse(order, http.StatusCreated)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request, orderID string) {
+	if _, ok := s.authorize(r); !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	s.mu.Lock()
+	order, ok := s.orders[orderID]
+	var resp *api.OrderResponse
+	if ok {
+		resp = orderResponse(order, http.StatusOK)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleConfirmOrder(w http.ResponseWriter, r *http.Request, orderID string) {
+	if _, ok := s.authorize(r); !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req struct {
+		TxHash string `json:"txHash"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TxHash == "" {
+		writeError(w, http.StatusBadRequest, "txHash is required")
+		return
+	}
+
+	if !s.ConfirmOrder(orderID, req.TxHash) {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"statusCode": http.StatusOK,
+		"data": map[string]interface{}{
+			"success": true,
+			"message": "order confirmed",
+		},
+	})
+}
+
+func (s *Server) authorize(r *http.Request) (string, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	address, ok := s.tokens[token]
+	return address, ok
+}
+
+func signIntegrity(key *ecdsa.PrivateKey, order *Order, voter common.Address) (string, string) {
+	hashed := wallet.HashRequestData(order.RequestData)
+	digest := wallet.IntegrityHash(order.CandidateID, order.FeedAmount, order.ID, hashed, voter)
+	sig, _ := crypto.Sign(wallet.HashPersonalMessage(string(digest.Bytes())).Bytes(), key)
+	sig[64] += 27
+	return hashed.Hex(), hexutil.Encode(sig)
+}
+
+func orderResponse(order *Order, status int) *api.OrderResponse {
+	var resp api.OrderResponse
+	resp.StatusCode = status
+	resp.Time = time.Now().UTC().Format(time.RFC3339)
+	resp.Data.Order.ID = order.ID
+	resp.Data.Order.Status = order.Status
+	resp.Data.Order.FeedAmount = order.FeedAmount
+	resp.Data.Payment.ContractAddress = order.Payment.ContractAddress
+	resp.Data.Payment.FunctionName = order.Payment.FunctionName
+	json.Unmarshal([]byte(feedABI), &resp.Data.Payment.ABI)
+	resp.Data.Payment.Params.CandidateID = order.CandidateID
+	resp.Data.Payment.Params.FeedAmount = order.FeedAmount
+	resp.Data.Payment.Params.RequestID = order.ID
+	resp.Data.Payment.Params.RequestData = order.RequestData
+	resp.Data.Payment.Params.UserHashedMessage = order.Payment.UserHashedMessage
+	resp.Data.Payment.Params.IntegritySignature = order.Payment.IntegritySignature
+	return &resp
+}
+
+func writeResponse(w http.ResponseWriter, resp *Response) {
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	writeJSON(w, resp.Status, resp.Body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"statusCode": status,
+		"message":    message,
+	})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
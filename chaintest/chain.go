@@ -0,0 +1,108 @@
+package chaintest
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+const ChainID = 1337
+
+// FedEventABI describes the event the stub emits, for decoding its logs.
+const FedEventABI = `[{"type":"event","name":"Fed","anonymous":false,"inputs":[
+	{"name":"voter","type":"address","indexed":true},
+	{"name":"feedAmount","type":"uint256","indexed":false}]}]`
+
+var (
+	FeedStubAddress = common.HexToAddress("0x0000000000000000000000000000000000000AC1")
+
+	// FedEventTopic is emitted by the stub as Fed(address indexed voter, uint256 feedAmount).
+	FedEventTopic = crypto.Keccak256Hash([]byte("Fed(address,uint256)"))
+
+	feedSelector = crypto.Keccak256([]byte("feed(string,uint256,string,string,bytes,bytes)"))[:4]
+)
+
+// FeedStubCode returns runtime bytecode that reverts unless called with the
+// feed selector and otherwise emits Fed(msg.sender, feedAmount).
+func FeedStubCode() []byte {
+	code := []byte{
+		0x60, 0x00, 0x35, // PUSH1 0 CALLDATALOAD
+		0x60, 0xe0, 0x1c, // PUSH1 224 SHR
+		0x63, feedSelector[0], feedSelector[1], feedSelector[2], feedSelector[3], // PUSH4 selector
+		0x14,             // EQ
+		0x60, 0x13, 0x57, // PUSH1 0x13 JUMPI
+		0x60, 0x00, 0x80, 0xfd, // PUSH1 0 DUP1 REVERT
+		0x5b,             // JUMPDEST
+		0x60, 0x24, 0x35, // PUSH1 0x24 CALLDATALOAD
+		0x60, 0x00, 0x52, // PUSH1 0 MSTORE
+		0x33, // CALLER
+		0x7f, // PUSH32 topic
+	}
+	code = append(code, FedEventTopic.Bytes()...)
+	code = append(code,
+		0x60, 0x20, 0x60, 0x00, 0xa2, // PUSH1 32 PUSH1 0 LOG2
+		0x00, // STOP
+	)
+	return code
+}
+
+type Chain struct {
+	Backend *simulated.Backend
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New starts a simulated chain with the feed stub deployed at FeedStubAddress
+// and each account funded with 100 ether. Blocks are mined every blockTime.
+func New(blockTime time.Duration, accounts ...common.Address) *Chain {
+	alloc := types.GenesisAlloc{
+		FeedStubAddress: {Code: FeedStubCode(), Balance: big.NewInt(0)},
+	}
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	for _, account := range accounts {
+		alloc[account] = types.Account{Balance: funds}
+	}
+
+	c := &Chain{
+		Backend: simulated.NewBackend(alloc),
+		stop:    make(chan struct{}),
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(blockTime)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.Backend.Commit()
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+func (c *Chain) Dialer() wallet.Dialer {
+	return func(ctx context.Context, rpcURL string) (wallet.EthClient, error) {
+		return c.Backend.Client(), nil
+	}
+}
+
+func (c *Chain) Close() {
+	close(c.stop)
+	c.wg.Wait()
+	c.Backend.Close()
+}
@@ -0,0 +1,57 @@
+package chaintest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+// TestPrivateKey is the key of the first wallet in every Env.
+const TestPrivateKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+// Env is a simulated chain and a mock API whose orders pay the feed stub,
+// with funded wallets dialing the chain.
+type Env struct {
+	Wallet  *wallet.Wallet
+	Wallets []*wallet.Wallet
+	Chain   *Chain
+	Server  *apitest.Server
+}
+
+// NewEnv funds a wallet for TestPrivateKey and each of extraKeys on a new
+// chain and starts a mock API. Everything is closed when t finishes.
+func NewEnv(t testing.TB, extraKeys ...string) *Env {
+	t.Helper()
+
+	var wallets []*wallet.Wallet
+	var accounts []common.Address
+	for _, key := range append([]string{TestPrivateKey}, extraKeys...) {
+		w, err := wallet.NewWallet(key)
+		if err != nil {
+			t.Fatalf("NewWallet: %v", err)
+		}
+		wallets = append(wallets, w)
+		accounts = append(accounts, common.HexToAddress(w.GetAddress()))
+	}
+
+	chain := New(100*time.Millisecond, accounts...)
+	for _, w := range wallets {
+		w.SetDialer(chain.Dialer())
+		w.SetPollInterval(50 * time.Millisecond)
+	}
+
+	server := apitest.NewServer()
+	server.Payment.ContractAddress = FeedStubAddress.Hex()
+
+	t.Cleanup(func() {
+		for _, w := range wallets {
+			w.Close()
+		}
+		server.Close()
+		chain.Close()
+	})
+	return &Env{Wallet: wallets[0], Wallets: wallets, Chain: chain, Server: server}
+}
@@ -0,0 +1,235 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+)
+
+const DefaultFile = "aicraft-pending.json"
+
+// Entry links an order to the transaction that paid for it until the order
+// is confirmed. TxHash is empty while the order exists but is still unpaid.
+type Entry struct {
+	CreatedAt       time.Time `json:"createdAt"`
+	Wallet          string    `json:"wallet"`
+	OrderID         string    `json:"orderId"`
+	TxHash          string    `json:"txHash"`
+	RPCURL          string    `json:"rpcUrl"`
+	ChainID         int64     `json:"chainId"`
+	WalletID        string    `json:"walletId,omitempty"`
+	CandidateID     string    `json:"candidateId"`
+	TargetCountryID string    `json:"targetCountryId"`
+	FeedAmount      int       `json:"feedAmount"`
+	Stage           string    `json:"stage,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Pending reports whether result sent a transaction but never got its order
+// confirmed, and so needs a checkpoint. A reverted transaction can never
+// confirm its order, so it is not pending.
+func Pending(result pipeline.VoteResult) bool {
+	return result.Status == pipeline.StatusFailed && result.OrderID != "" && result.TxHash != "" && !Reverted(result)
+}
+
+// Reverted reports whether result failed because its transaction was mined
+// and reverted.
+func Reverted(result pipeline.VoteResult) bool {
+	return result.Stage == pipeline.StageWaitReceipt && result.BlockNumber != 0
+}
+
+func FromResult(job pipeline.VoteJob, result pipeline.VoteResult, at time.Time) Entry {
+	return Entry{
+		CreatedAt:       at.UTC(),
+		Wallet:          result.WalletAddress,
+		OrderID:         result.OrderID,
+		TxHash:          result.TxHash,
+		RPCURL:          job.RPCURL,
+		ChainID:         job.ChainID,
+		WalletID:        job.WalletID,
+		CandidateID:     job.CandidateID,
+		TargetCountryID: job.TargetCountryID,
+		FeedAmount:      job.FeedAmount,
+		Stage:           string(result.Stage),
+		Error:           result.Error,
+	}
+}
+
+func (e Entry) Job() pipeline.VoteJob {
+	return pipeline.VoteJob{
+		RPCURL:          e.RPCURL,
+		ChainID:         e.ChainID,
+		WalletID:        e.WalletID,
+		CandidateID:     e.CandidateID,
+		TargetCountryID: e.TargetCountryID,
+		FeedAmount:      e.FeedAmount,
+	}
+}
+
+// Store keeps pending entries in a small JSON file that is rewritten
+// atomically on every change, so a crash never leaves it half written.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+func Open(path string) *Store {
+	if path == "" {
+		path = DefaultFile
+	}
+	return &Store{path: path}
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Add records e, replacing any earlier entry for the same order as well as
+// any unpaid order for the same wallet and candidate that e supersedes.
+func (s *Store) Add(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, old := range entries {
+		superseded := old.TxHash == "" && strings.EqualFold(old.Wallet, e.Wallet) && old.CandidateID == e.CandidateID
+		if old.OrderID != e.OrderID && !superseded {
+			kept = append(kept, old)
+		}
+	}
+	return s.save(append(kept, e))
+}
+
+// PendingOrder implements pipeline.PendingOrders.
+func (s *Store) PendingOrder(walletAddress, candidateID string) (string, bool) {
+	entries, err := s.List()
+	if err != nil {
+		slog.Warn("failed to read checkpoints", "error", err)
+		return "", false
+	}
+	for _, e := range entries {
+		if e.TxHash == "" && strings.EqualFold(e.Wallet, walletAddress) && e.CandidateID == candidateID {
+			return e.OrderID, true
+		}
+	}
+	return "", false
+}
+
+// ObserveEvent is a pipeline.Hook that journals each order as soon as it is
+// created and again once its transaction is sent. An order that fails
+// validation is dropped so that later runs do not offer it for reuse.
+func (s *Store) ObserveEvent(ev pipeline.Event) {
+	if ev.Result == nil {
+		return
+	}
+	if ev.Kind == pipeline.EventFailed && ev.Stage == pipeline.StageValidateOrder && ev.Result.OrderID != "" {
+		if err := s.Remove(ev.Result.OrderID); err != nil {
+			slog.Warn("failed to remove rejected order from checkpoints", "order_id", ev.Result.OrderID, "error", err)
+		}
+		return
+	}
+	if ev.Kind != pipeline.EventCompleted {
+		return
+	}
+	if ev.Stage != pipeline.StageCreateOrder && ev.Stage != pipeline.StageSendTransaction {
+		return
+	}
+	if err := s.Add(FromResult(ev.Job, *ev.Result, time.Now())); err != nil {
+		slog.Warn("failed to journal order", "order_id", ev.Result.OrderID, "error", err)
+	}
+}
+
+// ObserveResult drops the journal entry of a confirmed vote or of one whose
+// transaction reverted.
+func (s *Store) ObserveResult(result pipeline.VoteResult) {
+	if result.OrderID == "" || (result.Status != pipeline.StatusConfirmed && !Reverted(result)) {
+		return
+	}
+	if err := s.Remove(result.OrderID); err != nil {
+		slog.Warn("failed to remove order from checkpoints", "order_id", result.OrderID, "error", err)
+	}
+}
+
+func (s *Store) Remove(orderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	return s.save(without(entries, orderID))
+}
+
+func without(entries []Entry, orderID string) []Entry {
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.OrderID != orderID {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func (s *Store) load() ([]Entry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %v", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file %s: %v", s.path, err)
+	}
+	return entries, nil
+}
+
+func (s *Store) save(entries []Entry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove checkpoint file: %v", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoints: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package checkpoint_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/checkpoint"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+)
+
+func TestStoreAddReplaceRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pending.json")
+	store := checkpoint.Open(path)
+
+	job := pipeline.VoteJob{RPCURL: "http://localhost:8545", ChainID: 10143, CandidateID: "678dbb6579af53b8da5ddf3d", FeedAmount: 2}
+	result := pipeline.VoteResult{
+		WalletAddress: "0xabc",
+		OrderID:       "order-1",
+		TxHash:        "0x01",
+		Stage:         pipeline.StageWaitReceipt,
+		Status:        pipeline.StatusFailed,
+	}
+	if !checkpoint.Pending(result) {
+		t.Fatal("failed result with order and tx hash should be pending")
+	}
+
+	now := time.Now()
+	if err := store.Add(checkpoint.FromResult(job, result, now)); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	result.Stage = pipeline.StageConfirmOrder
+	if err := store.Add(checkpoint.FromResult(job, result, now)); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	other := result
+	other.OrderID, other.TxHash = "order-2", "0x02"
+	if err := store.Add(checkpoint.FromResult(job, other, now)); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Stage != string(pipeline.StageConfirmOrder) || entries[1].OrderID != "order-2" {
+		t.Fatalf("entries = %+v, want order-1 replaced and order-2 appended", entries)
+	}
+	if got := entries[0].Job(); !reflect.DeepEqual(got, job) {
+		t.Fatalf("Job() = %+v, want %+v", got, job)
+	}
+
+	for _, id := range []string{"order-1", "order-2"} {
+		if err := store.Remove(id); err != nil {
+			t.Fatalf("Remove(%s): %v", id, err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("checkpoint file should be removed once empty, stat err = %v", err)
+	}
+}
+
+func TestPendingIgnoresResultsWithoutTransaction(t *testing.T) {
+	for _, r := range []pipeline.VoteResult{
+		{Status: pipeline.StatusConfirmed, OrderID: "o", TxHash: "0x01"},
+		{Status: pipeline.StatusFailed, OrderID: "o"},
+		{Status: pipeline.StatusSkipped},
+	} {
+		if checkpoint.Pending(r) {
+			t.Errorf("Pending(%+v) = true", r)
+		}
+	}
+}
+
+func TestStoreJournalsOrdersUntilConfirmed(t *testing.T) {
+	store := checkpoint.Open(filepath.Join(t.TempDir(), "pending.json"))
+	job := pipeline.VoteJob{CandidateID: "678dbb6579af53b8da5ddf3d", FeedAmount: 1}
+	result := &pipeline.VoteResult{WalletAddress: "0xAbC", OrderID: "order-1"}
+
+	store.ObserveEvent(pipeline.Event{Kind: pipeline.EventCompleted, Stage: pipeline.StageCreateOrder, Job: job, Result: result})
+	if id, ok := store.PendingOrder("0xabc", job.CandidateID); !ok || id != "order-1" {
+		t.Fatalf("PendingOrder = %q, %t; want order-1", id, ok)
+	}
+
+	// A newer unpaid order for the same vote supersedes the old one.
+	result.OrderID = "order-2"
+	store.ObserveEvent(pipeline.Event{Kind: pipeline.EventCompleted, Stage: pipeline.StageCreateOrder, Job: job, Result: result})
+	if entries, _ := store.List(); len(entries) != 1 || entries[0].OrderID != "order-2" {
+		t.Fatalf("entries = %+v, want only order-2", entries)
+	}
+
+	result.TxHash = "0x02"
+	store.ObserveEvent(pipeline.Event{Kind: pipeline.EventCompleted, Stage: pipeline.StageSendTransaction, Job: job, Result: result})
+	if _, ok := store.PendingOrder("0xabc", job.CandidateID); ok {
+		t.Fatal("a paid order must not be offered for reuse")
+	}
+
+	result.Status = pipeline.StatusConfirmed
+	store.ObserveResult(*result)
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Fatalf("entries = %+v, want none after confirmation", entries)
+	}
+}
+
+func TestStoreDropsOrdersThatFailValidation(t *testing.T) {
+	store := checkpoint.Open(filepath.Join(t.TempDir(), "pending.json"))
+	job := pipeline.VoteJob{CandidateID: "678dbb6579af53b8da5ddf3d", FeedAmount: 1}
+	result := &pipeline.VoteResult{WalletAddress: "0xabc", OrderID: "order-1"}
+
+	store.ObserveEvent(pipeline.Event{Kind: pipeline.EventCompleted, Stage: pipeline.StageCreateOrder, Job: job, Result: result})
+	store.ObserveEvent(pipeline.Event{Kind: pipeline.EventFailed, Stage: pipeline.StageValidateOrder, Job: job, Result: result})
+	if id, ok := store.PendingOrder("0xabc", job.CandidateID); ok {
+		t.Fatalf("PendingOrder = %q, want the rejected order dropped", id)
+	}
+}
+
+func TestStoreDropsRevertedTransactions(t *testing.T) {
+	store := checkpoint.Open(filepath.Join(t.TempDir(), "pending.json"))
+	job := pipeline.VoteJob{CandidateID: "678dbb6579af53b8da5ddf3d", FeedAmount: 1}
+	unknown := pipeline.VoteResult{
+		WalletAddress: "0xabc",
+		OrderID:       "order-1",
+		TxHash:        "0x01",
+		Stage:         pipeline.StageWaitReceipt,
+		Status:        pipeline.StatusFailed,
+		Error:         "context deadline exceeded",
+	}
+	if !checkpoint.Pending(unknown) {
+		t.Fatal("a transaction without a receipt should be pending")
+	}
+	if err := store.Add(checkpoint.FromResult(job, unknown, time.Now())); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	reverted := unknown
+	reverted.BlockNumber = 42
+	reverted.Error = "transaction 0x01 reverted"
+	if checkpoint.Pending(reverted) {
+		t.Fatal("a reverted transaction can never be confirmed and must not be pending")
+	}
+	store.ObserveResult(reverted)
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Fatalf("entries = %+v, want the reverted order dropped", entries)
+	}
+}
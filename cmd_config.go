@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/logging"
+)
+
+type configValidateResult struct {
+	Valid    bool     `json:"valid"`
+	Profile  string   `json:"profile,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func configCommand(args []string) {
+	if len(args) == 0 || args[0] != "validate" {
+		fatal("❌ Unknown config command", fmt.Errorf("usage: config validate [flags]"))
+	}
+
+	flags := newCommandFlags("config validate")
+	offline := flags.fs.Bool("offline", false, "skip checks that need the RPC endpoint")
+	out := flags.parse(args[1:])
+
+	cfg, err := flags.loadConfig(true)
+	if err != nil {
+		out.result(&configValidateResult{Error: logging.Redact(err.Error())})
+		fatal("❌ Failed to load config", err)
+	}
+
+	if *offline {
+		err = cfg.Validate()
+	} else {
+		err = cfg.ValidateWithRPC(context.Background())
+	}
+	if err != nil {
+		result := &configValidateResult{Profile: cfg.Profile, Error: logging.Redact(err.Error())}
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			for _, problem := range verr.Problems {
+				result.Problems = append(result.Problems, logging.Redact(problem))
+			}
+		}
+		out.result(result)
+		out.Printf("%s\n", err)
+		fatal("❌ Invalid configuration", errors.New("see problems above"))
+	}
+
+	out.printConfig(cfg)
+	out.Printf("✅ Configuration is valid\n")
+	out.result(&configValidateResult{Valid: true, Profile: cfg.Profile})
+}
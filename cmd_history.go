@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/history"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+)
+
+func historyCommand(args []string) {
+	flags := newCommandFlags("history")
+	walletFilter := flags.fs.String("wallet", "", "only show votes from this wallet address")
+	status := flags.fs.String("status", "", "only show votes with this status (confirmed, failed, skipped)")
+	since := flags.fs.String("since", "", "only show votes at or after this date (YYYY-MM-DD or RFC3339)")
+	until := flags.fs.String("until", "", "only show votes before this date (YYYY-MM-DD or RFC3339)")
+	limit := flags.fs.Int("limit", 0, "only show the most recent N votes")
+	csvPath := flags.fs.String("csv", "", "export matching votes as CSV to this file (- for stdout)")
+	out := flags.parse(args)
+
+	cfg, err := flags.loadConfig(true)
+	if err != nil {
+		fatal("❌ Failed to load config", err)
+	}
+
+	filter := history.Filter{Wallet: *walletFilter, Status: *status, Limit: *limit}
+	if filter.Since, err = parseDate(*since); err != nil {
+		fatal("❌ Invalid -since", err)
+	}
+	if filter.Until, err = parseDate(*until); err != nil {
+		fatal("❌ Invalid -until", err)
+	}
+
+	store, err := history.Open(cfg.HistoryFile)
+	if err != nil {
+		fatal("❌ Failed to open vote history", err)
+	}
+	records, err := store.List(filter)
+	if err != nil {
+		fatal("❌ Failed to read vote history", err)
+	}
+
+	if *csvPath != "" {
+		w := os.Stdout
+		if *csvPath != "-" {
+			f, err := os.Create(*csvPath)
+			if err != nil {
+				fatal("❌ Failed to create CSV file", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := history.WriteCSV(w, records); err != nil {
+			fatal("❌ Failed to write CSV", err)
+		}
+		if *csvPath != "-" {
+			fmt.Fprintf(os.Stderr, "📁 Exported %d votes to %s\n", len(records), *csvPath)
+		}
+		return
+	}
+
+	if records == nil {
+		records = []history.Record{}
+	}
+	out.result(records)
+
+	out.Printf("\n📜 Vote history (%s): %d votes\n", store.Path(), len(records))
+	for _, r := range records {
+		icon := "✅"
+		if r.Status != pipeline.StatusConfirmed {
+			icon = "❌"
+		}
+		out.Printf("%s %s %s candidate=%s country=%s feed=%d", icon, r.Timestamp.Local().Format("2006-01-02 15:04:05"), r.Wallet, r.CandidateID, r.CountryID, r.FeedAmount)
+		if r.TxHash != "" {
+			out.Printf(" tx=%s", r.TxHash)
+		}
+		if r.FeePaid != "" {
+			out.Printf(" fee=%s", r.FeePaid)
+		}
+		if r.Error != "" {
+			out.Printf(" error=%q", r.Error)
+		}
+		out.Printf("\n")
+	}
+}
+
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not YYYY-MM-DD or RFC3339", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/logging"
+)
+
+func mockAPICommand(args []string) {
+	fs := flag.NewFlagSet("mock-api", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "listen address")
+	contract := fs.String("contract", apitest.DefaultContractAddress, "contract address returned in order payments")
+	signInFormat := fs.String("sign-in-format", string(api.SignInPersonal), "sign-in message format: personal_sign, eip712 or siwe")
+	chainID := fs.Int64("chain-id", config.DefaultChainID, "chain ID named in SIWE and EIP-712 sign-in messages")
+	logLevel := fs.String("log-level", "info", "log level: debug, info, warn or error")
+	fs.Parse(args)
+
+	logger, err := logging.New(os.Stderr, *logLevel, "text")
+	if err != nil {
+		fatal("❌ Failed to initialize logger", err)
+	}
+	slog.SetDefault(logger)
+
+	server := apitest.New()
+	server.Payment.ContractAddress = *contract
+	server.SignInFormat = api.SignInFormat(*signInFormat)
+	server.ChainID = *chainID
+
+	fmt.Printf("🧪 Mock AICraft API listening on http://%s\n", *addr)
+	fmt.Printf("• Use API_BASE_URL=http://%s or --api-url to point the bot at it\n", *addr)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		fatal("❌ Mock API server stopped", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/checkpoint"
+	"github.com/nekowawolf/aicraft-bot/history"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+// resumeCommand confirms the orders that a previous run checkpointed after
+// sending their transaction.
+func resumeCommand(args []string) {
+	flags := newCommandFlags("resume")
+	out := flags.parse(args)
+
+	cfg, err := flags.loadConfig(false)
+	if err != nil {
+		fatal("❌ Failed to load config", err)
+	}
+
+	checkpoints := checkpoint.Open(cfg.CheckpointFile)
+	all, err := checkpoints.List()
+	if err != nil {
+		fatal("❌ Failed to read checkpoints", err)
+	}
+	// Orders without a transaction were never paid for; the next run reuses them.
+	var entries []checkpoint.Entry
+	for _, entry := range all {
+		if entry.TxHash != "" {
+			entries = append(entries, entry)
+		}
+	}
+	summary := &pipeline.Summary{}
+	if len(entries) == 0 {
+		out.Printf("✅ No unconfirmed orders in %s\n", checkpoints.Path())
+		out.result(summary)
+		return
+	}
+
+	store, err := history.Open(cfg.HistoryFile)
+	if err != nil {
+		fatal("❌ Failed to open vote history", err)
+	}
+
+	limits := wallet.NewRateLimits(cfg.RPCRateLimit, cfg.RPCBurst)
+	wallets := make(map[string]*wallet.Wallet)
+	for i, key := range cfg.Keys() {
+		w, err := wallet.NewWallet(key)
+		if err != nil {
+			fatal("❌ Failed to initialize wallet", fmt.Errorf("key %d: %v", i+1, err))
+		}
+		defer w.Close()
+		w.SetDialer(limits.Dialer(nil))
+		wallets[strings.ToLower(w.GetAddress())] = w
+	}
+
+	client := api.NewClientWithOptions(cfg.APIBaseURL, api.Options{
+		RequestsPerSecond: cfg.APIRateLimit,
+		Burst:             cfg.APIBurst,
+		MaxRetries:        cfg.APIMaxRetries,
+		SignInFormat:      api.SignInFormat(cfg.SignInFormat),
+		SignInDomain:      cfg.SignInDomain,
+		ChainID:           cfg.ChainID,
+	})
+	stopping, ctx := handleShutdown(time.Duration(cfg.GraceSeconds) * time.Second)
+	out.prefixWallet = len(entries) > 1
+
+	for _, entry := range entries {
+		w, ok := wallets[strings.ToLower(entry.Wallet)]
+		if !ok || isClosed(stopping) {
+			reason := "no configured private key for this wallet"
+			if ok {
+				reason = "shutting down"
+			}
+			summary.Add(pipeline.VoteResult{
+				WalletAddress: entry.Wallet,
+				OrderID:       entry.OrderID,
+				TxHash:        entry.TxHash,
+				Status:        pipeline.StatusSkipped,
+				Error:         reason,
+			})
+			continue
+		}
+
+		out.Printf("🔁 Resuming order %s (tx %s)\n", entry.OrderID, entry.TxHash)
+		runner := pipeline.NewRunner(w, client)
+		runner.MaxAttempts = cfg.MaxAttempts
+		runner.RetryDelay = time.Duration(cfg.DelaySeconds) * time.Second
+		runner.OnEvent(out.progress)
+
+		result, _ := runner.Resume(ctx, entry.Job(), entry.OrderID, entry.TxHash)
+		summary.Add(result)
+		if err := store.Append(history.FromResult(result, time.Now())); err != nil {
+			slog.Warn("failed to record vote history", "error", err)
+		}
+		checkpoints.ObserveResult(result)
+		if checkpoint.Pending(result) {
+			saveCheckpoint(checkpoints, checkpoint.FromResult(entry.Job(), result, time.Now()))
+		}
+	}
+
+	out.printSummary(summary)
+	out.result(summary)
+	if summary.Failed > 0 {
+		fatal("❌ Some orders could not be confirmed", fmt.Errorf("%d of %d orders failed", summary.Failed, len(summary.Results)))
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/history"
+	"github.com/nekowawolf/aicraft-bot/spend"
+)
+
+func newSpendTracker(cfg *config.Config, store *history.Store) (*spend.Tracker, error) {
+	limits, err := spend.LimitsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	records, err := store.List(history.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	return spend.NewTracker(limits, records), nil
+}
+
+func spendCommand(args []string) {
+	if len(args) == 0 || args[0] != "report" {
+		fatal("❌ Unknown spend command", fmt.Errorf("usage: spend report [flags]"))
+	}
+
+	flags := newCommandFlags("spend report")
+	out := flags.parse(args[1:])
+
+	cfg, err := flags.loadConfig(true)
+	if err != nil {
+		fatal("❌ Failed to load config", err)
+	}
+	store, err := history.Open(cfg.HistoryFile)
+	if err != nil {
+		fatal("❌ Failed to open vote history", err)
+	}
+	tracker, err := newSpendTracker(cfg, store)
+	if err != nil {
+		fatal("❌ Failed to load spend history", err)
+	}
+
+	report := tracker.Report()
+	out.result(report)
+
+	out.Printf("\n💸 Spend report (UTC day %s)\n", report.Day)
+	out.Printf("• All wallets: %s today%s, %s total%s\n",
+		spend.FormatAmount(report.Today), budgetSuffix(report.Limits.GlobalDaily),
+		spend.FormatAmount(report.Total), budgetSuffix(report.Limits.GlobalTotal))
+	for _, w := range report.Wallets {
+		out.Printf("• %s: %s today%s, %s total%s\n", w.Wallet,
+			spend.FormatAmount(w.Today), budgetSuffix(report.Limits.WalletDaily),
+			spend.FormatAmount(w.Total), budgetSuffix(report.Limits.WalletTotal))
+	}
+}
+
+func budgetSuffix(limit *big.Int) string {
+	if limit == nil {
+		return ""
+	}
+	return " / " + spend.FormatAmount(limit)
+}
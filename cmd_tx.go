@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nekowawolf/aicraft-bot/spend"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+func txCommand(args []string) {
+	if len(args) == 0 || args[0] != "inspect" {
+		fatal("❌ Unknown tx command", errors.New("usage: tx inspect <hash>"))
+	}
+	args = args[1:]
+
+	flags := newCommandFlags("tx inspect")
+	abiFile := flags.fs.String("abi", "", "JSON ABI file whose events decode the logs (by default logs are shown undecoded)")
+	var hash string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		hash, args = args[0], args[1:]
+	}
+	out := flags.parse(args)
+	if hash == "" {
+		hash = flags.fs.Arg(0)
+	}
+	if b, err := hexutil.Decode(hash); err != nil || len(b) != common.HashLength {
+		fatal("❌ Invalid transaction hash", fmt.Errorf("%q (usage: tx inspect <hash>)", hash))
+	}
+
+	cfg, err := flags.loadConfig(true)
+	if err != nil {
+		fatal("❌ Failed to load config", err)
+	}
+
+	definition := wallet.FeedABI
+	if *abiFile != "" {
+		data, err := os.ReadFile(*abiFile)
+		if err != nil {
+			fatal("❌ Failed to read ABI file", err)
+		}
+		definition = string(data)
+	}
+	contractABI, err := wallet.ParseABI(definition)
+	if err != nil {
+		fatal("❌ Failed to load ABI", err)
+	}
+
+	ctx := context.Background()
+	client, err := wallet.NewRateLimits(cfg.RPCRateLimit, cfg.RPCBurst).Dialer(nil)(ctx, cfg.RPCURL)
+	if err != nil {
+		fatal("❌ Failed to connect to RPC", err)
+	}
+	if closer, ok := client.(interface{ Close() }); ok {
+		defer closer.Close()
+	}
+
+	ins, err := wallet.InspectTransaction(ctx, client, common.HexToHash(hash), contractABI)
+	if err != nil {
+		fatal("❌ Failed to inspect transaction", err)
+	}
+	out.result(ins)
+	out.printInspection(ins)
+}
+
+func (p *printer) printInspection(ins *wallet.TxInspection) {
+	p.Printf("\n🔎 Transaction %s\n", ins.Hash)
+	switch {
+	case ins.Pending:
+		p.Printf("• Status: ⏳ pending\n")
+	case ins.Status == "success":
+		p.Printf("• Status: ✅ success (block %d)\n", ins.BlockNumber)
+	default:
+		p.Printf("• Status: ❌ %s (block %d)\n", ins.Status, ins.BlockNumber)
+	}
+	p.Printf("• From: %s\n", ins.From)
+	p.Printf("• To: %s\n", ins.To)
+	p.Printf("• Chain ID: %d, nonce: %d\n", ins.ChainID, ins.Nonce)
+	if ins.Value != "0" {
+		p.Printf("• Value: %s\n", formatWei(ins.Value))
+	}
+
+	p.Printf("\n⛽ Gas:\n")
+	if ins.Pending {
+		p.Printf("• Limit: %d\n", ins.GasLimit)
+	} else {
+		p.Printf("• Used: %d of %d (%.1f%%)\n", ins.GasUsed, ins.GasLimit, 100*float64(ins.GasUsed)/float64(ins.GasLimit))
+		p.Printf("• Effective gas price: %s\n", formatGwei(ins.EffectiveGasPrice))
+	}
+	p.Printf("• Max fee: %s, priority fee: %s\n", formatGwei(ins.GasFeeCap), formatGwei(ins.GasTipCap))
+	if ins.Fee != "" {
+		p.Printf("• Fee paid: %s\n", formatWei(ins.Fee))
+	}
+
+	if call := ins.Call; call != nil {
+		p.Printf("\n🗳️ feed call:\n")
+		p.Printf("• Candidate ID: %s\n", call.CandidateID)
+		p.Printf("• Feed amount: %s\n", call.FeedAmount)
+		p.Printf("• Request ID: %s\n", call.RequestID)
+		p.Printf("• Request data: %s\n", call.RequestData)
+		p.Printf("• User hashed message: %s\n", call.UserHashedMessage)
+		p.Printf("• Integrity signature: %s\n", call.IntegritySignature)
+	} else {
+		p.Printf("\n⚠️ Calldata not decoded: %s\n", ins.CallError)
+	}
+
+	if len(ins.Logs) > 0 {
+		p.Printf("\n📜 Logs:\n")
+	}
+	for _, log := range ins.Logs {
+		if log.Event == "" {
+			p.Printf("• [%d] %s unknown event, topics=%v data=%s\n", log.Index, log.Address, log.Topics, log.Data)
+			continue
+		}
+		p.Printf("• [%d] %s %s", log.Index, log.Address, log.Event)
+		names := make([]string, 0, len(log.Fields))
+		for name := range log.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p.Printf(" %s=%v", name, log.Fields[name])
+		}
+		if log.Error != "" {
+			p.Printf(" (%s)", log.Error)
+		}
+		p.Printf("\n")
+	}
+	p.Printf("\n")
+}
+
+func formatWei(wei string) string {
+	amount, ok := new(big.Int).SetString(wei, 10)
+	if !ok {
+		return wei
+	}
+	return spend.FormatAmount(amount)
+}
+
+func formatGwei(wei string) string {
+	amount, ok := new(big.Float).SetString(wei)
+	if !ok {
+		return wei
+	}
+	return new(big.Float).Quo(amount, big.NewFloat(1e9)).Text('f', 3) + " gwei"
+}
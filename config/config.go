@@ -1,66 +1,283 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultConfigFile = "aicraft.yaml"
+	DefaultRPCURL     = "https://testnet-rpc.monad.xyz"
+	DefaultChainID    = 10143
+	DefaultAPIBaseURL = "https://api.aicraft.fun"
 )
 
 type Config struct {
-	PrivateKey      string `envconfig:"PRIVATE_KEY" required:"true"`
-	RPCURL          string `envconfig:"RPC_URL" default:"https://testnet-rpc.monad.xyz"`
-	WalletID        string `envconfig:"WALLET_ID" required:"true"`
-	ChainID         int64  `envconfig:"CHAIN_ID" default:"10143"` // Diubah menjadi int64
-	TargetCountry   string `envconfig:"TARGET_COUNTRY"`
-	TargetCountryID string `envconfig:"TARGET_COUNTRY_ID" required:"true"`
-	CandidateID     string `envconfig:"CANDIDATE_ID" required:"true"`
-	FeedAmount      int    `envconfig:"FEED_AMOUNT" default:"1"`
-	DelaySeconds    int    `envconfig:"DELAY_SECONDS" default:"5"`
+	Profile           string   `ignored:"true" yaml:"-"`
+	PrivateKey        string   `envconfig:"PRIVATE_KEY" yaml:"private_key"`
+	PrivateKeys       []string `envconfig:"PRIVATE_KEYS" yaml:"private_keys"`
+	RPCURL            string   `envconfig:"RPC_URL" yaml:"rpc_url"`
+	WalletID          string   `envconfig:"WALLET_ID" yaml:"wallet_id"`
+	ChainID           int64    `envconfig:"CHAIN_ID" yaml:"chain_id"`
+	APIBaseURL        string   `envconfig:"API_BASE_URL" yaml:"api_base_url"`
+	TargetCountry     string   `envconfig:"TARGET_COUNTRY" yaml:"target_country"`
+	TargetCountryID   string   `envconfig:"TARGET_COUNTRY_ID" yaml:"target_country_id"`
+	CandidateID       string   `envconfig:"CANDIDATE_ID" yaml:"candidate_id"`
+	FeedAmount        int      `envconfig:"FEED_AMOUNT" yaml:"feed_amount"`
+	MaxAttempts       int      `envconfig:"MAX_ATTEMPTS" yaml:"max_attempts"`
+	DelaySeconds      int      `envconfig:"DELAY_SECONDS" yaml:"delay_seconds"`
+	Workers           int      `envconfig:"WORKERS" yaml:"workers"`
+	VotesPerWallet    int      `envconfig:"VOTES_PER_WALLET" yaml:"votes_per_wallet"`
+	RPCRateLimit      float64  `envconfig:"RPC_RATE_LIMIT" yaml:"rpc_rate_limit"`
+	RPCBurst          int      `envconfig:"RPC_BURST" yaml:"rpc_burst"`
+	APIRateLimit      float64  `envconfig:"API_RATE_LIMIT" yaml:"api_rate_limit"`
+	APIBurst          int      `envconfig:"API_BURST" yaml:"api_burst"`
+	APIMaxRetries     int      `envconfig:"API_MAX_RETRIES" yaml:"api_max_retries"`
+	IntervalSeconds   int      `envconfig:"INTERVAL_SECONDS" yaml:"interval_seconds"`
+	HTTPAddr          string   `envconfig:"HTTP_ADDR" yaml:"http_addr"`
+	HistoryFile       string   `envconfig:"HISTORY_FILE" yaml:"history_file"`
+	CheckpointFile    string   `envconfig:"CHECKPOINT_FILE" yaml:"checkpoint_file"`
+	GraceSeconds      int      `envconfig:"SHUTDOWN_GRACE_SECONDS" yaml:"shutdown_grace_seconds"`
+	AllowedContracts  []string `envconfig:"ALLOWED_CONTRACTS" yaml:"allowed_contracts"`
+	IntegritySigner   string   `envconfig:"INTEGRITY_SIGNER" yaml:"integrity_signer"`
+	SignInFormat      string   `envconfig:"SIGN_IN_FORMAT" yaml:"sign_in_format"`
+	SignInDomain      string   `envconfig:"SIGN_IN_DOMAIN" yaml:"sign_in_domain"`
+	WebhookURLs       []string `envconfig:"WEBHOOK_URLS" yaml:"webhook_urls"`
+	WebhookSecret     string   `envconfig:"WEBHOOK_SECRET" yaml:"webhook_secret"`
+	LowBalance        string   `envconfig:"LOW_BALANCE" yaml:"low_balance"`
+	BudgetWalletDaily string   `envconfig:"BUDGET_WALLET_DAILY" yaml:"budget_wallet_daily"`
+	BudgetWalletTotal string   `envconfig:"BUDGET_WALLET_TOTAL" yaml:"budget_wallet_total"`
+	BudgetGlobalDaily string   `envconfig:"BUDGET_GLOBAL_DAILY" yaml:"budget_global_daily"`
+	BudgetGlobalTotal string   `envconfig:"BUDGET_GLOBAL_TOTAL" yaml:"budget_global_total"`
+	LogLevel          string   `envconfig:"LOG_LEVEL" yaml:"log_level"`
+	LogFormat         string   `envconfig:"LOG_FORMAT" yaml:"log_format"`
+}
+
+type Options struct {
+	File    string
+	Profile string
+	Flags   *Flags
+
+	SkipValidation bool
+}
+
+type fileConfig struct {
+	DefaultProfile string               `yaml:"default_profile"`
+	Profiles       map[string]yaml.Node `yaml:"profiles"`
 }
 
-func LoadConfig() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("Note: No .env file found, using environment variables")
+func Default() *Config {
+	return &Config{
+		RPCURL:         DefaultRPCURL,
+		ChainID:        DefaultChainID,
+		APIBaseURL:     DefaultAPIBaseURL,
+		FeedAmount:     1,
+		MaxAttempts:    3,
+		DelaySeconds:   5,
+		Workers:        4,
+		VotesPerWallet: 1,
+		RPCRateLimit:   10,
+		RPCBurst:       10,
+		APIRateLimit:   5,
+		APIBurst:       5,
+		APIMaxRetries:  3,
+		GraceSeconds:   30,
+		SignInFormat:   "personal_sign",
+		LogLevel:       "info",
+		LogFormat:      "text",
 	}
+}
 
-	var cfg Config
-	err := envconfig.Process("", &cfg)
-	if err != nil {
+// LoadConfig resolves the configuration with precedence flags > env > file > defaults.
+func LoadConfig(opts Options) (*Config, error) {
+	cfg := Default()
+
+	if opts.Flags != nil {
+		if opts.File == "" {
+			opts.File = opts.Flags.File
+		}
+		if opts.Profile == "" {
+			opts.Profile = opts.Flags.Profile
+		}
+	}
+	if opts.File == "" {
+		opts.File = os.Getenv("AICRAFT_CONFIG")
+	}
+	if opts.Profile == "" {
+		opts.Profile = os.Getenv("AICRAFT_PROFILE")
+	}
+
+	if err := loadFile(cfg, opts.File, opts.Profile); err != nil {
+		return nil, err
+	}
+
+	if err := envconfig.Process("", cfg); err != nil {
 		return nil, fmt.Errorf("failed to process env vars: %v", err)
 	}
 
+	if opts.Flags != nil {
+		opts.Flags.apply(cfg)
+	}
+
 	cfg.PrivateKey = strings.TrimSpace(cfg.PrivateKey)
+	for i, key := range cfg.PrivateKeys {
+		cfg.PrivateKeys[i] = strings.TrimSpace(key)
+	}
+	for i, c := range cfg.AllowedContracts {
+		cfg.AllowedContracts[i] = strings.TrimSpace(c)
+	}
+	for i, u := range cfg.WebhookURLs {
+		cfg.WebhookURLs[i] = strings.TrimSpace(u)
+	}
 	cfg.WalletID = strings.TrimSpace(cfg.WalletID)
 	cfg.TargetCountryID = strings.TrimSpace(cfg.TargetCountryID)
 	cfg.CandidateID = strings.TrimSpace(cfg.CandidateID)
 
-	if cfg.PrivateKey == "" {
-		return nil, fmt.Errorf("PRIVATE_KEY is required")
+	if cfg.RPCURL == "" {
+		cfg.RPCURL = DefaultRPCURL
 	}
-	if cfg.WalletID == "" {
-		return nil, fmt.Errorf("WALLET_ID is required")
+	if cfg.ChainID == 0 {
+		cfg.ChainID = DefaultChainID
 	}
-	if cfg.TargetCountryID == "" {
-		return nil, fmt.Errorf("TARGET_COUNTRY_ID is required")
+	if cfg.APIBaseURL == "" {
+		cfg.APIBaseURL = DefaultAPIBaseURL
 	}
-	if cfg.CandidateID == "" {
-		return nil, fmt.Errorf("CANDIDATE_ID is required")
+
+	if !opts.SkipValidation {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
-	if cfg.RPCURL == "" {
-		cfg.RPCURL = "https://testnet-rpc.monad.xyz"
+	return cfg, nil
+}
+
+func loadFile(cfg *Config, path, profile string) error {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigFile
 	}
 
-	if cfg.ChainID == 0 {
-		cfg.ChainID = 10143 
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			if profile != "" {
+				return fmt.Errorf("profile %q requested but no config file found", profile)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	var file fileConfig
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	if profile == "" {
+		profile = file.DefaultProfile
+	}
+	if profile == "" {
+		if len(file.Profiles) == 0 {
+			return nil
+		}
+		return fmt.Errorf("config file %s has no default_profile; select one of: %s", path, strings.Join(profileNames(file), ", "))
 	}
 
-	return &cfg, nil
+	node, ok := file.Profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s (available: %s)", profile, path, strings.Join(profileNames(file), ", "))
+	}
+	if err := node.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to parse profile %q in %s: %v", profile, path, err)
+	}
+	cfg.Profile = profile
+
+	return nil
+}
+
+func profileNames(file fileConfig) []string {
+	names := make([]string, 0, len(file.Profiles))
+	for name := range file.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Keys returns every private key to vote from: PRIVATE_KEY followed by PRIVATE_KEYS, without duplicates.
+func (c *Config) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range append([]string{c.PrivateKey}, c.PrivateKeys...) {
+		normalized := strings.ToLower(strings.TrimPrefix(key, "0x"))
+		if key == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		keys = append(keys, key)
+	}
+	return keys
 }
 
 func (c *Config) GetChainIDString() string {
 	return strconv.FormatInt(c.ChainID, 10)
-}
\ No newline at end of file
+}
+
+// AllowedContract is one ALLOWED_CONTRACTS entry, written as
+// [<chainID>:]<address>[=<code hash>]. ChainID is 0 for entries that apply to
+// every chain and CodeHash is empty when the bytecode is not pinned.
+type AllowedContract struct {
+	ChainID  int64
+	Address  string
+	CodeHash string
+}
+
+func ParseAllowedContract(entry string) (AllowedContract, error) {
+	scoped, codeHash, _ := strings.Cut(entry, "=")
+	chainID, address, err := parseChainScoped(scoped)
+	if err != nil {
+		return AllowedContract{}, err
+	}
+	return AllowedContract{ChainID: chainID, Address: strings.TrimSpace(address), CodeHash: strings.TrimSpace(codeHash)}, nil
+}
+
+// AllowedContractEntries returns the ALLOWED_CONTRACTS entries that apply to
+// chainID: unscoped entries apply to every chain, scoped ones only to theirs.
+func (c *Config) AllowedContractEntries(chainID int64) []AllowedContract {
+	var contracts []AllowedContract
+	for _, entry := range c.AllowedContracts {
+		contract, err := ParseAllowedContract(entry)
+		if err == nil && (contract.ChainID == 0 || contract.ChainID == chainID) {
+			contracts = append(contracts, contract)
+		}
+	}
+	return contracts
+}
+
+func (c *Config) AllowedContractsFor(chainID int64) []string {
+	var addresses []string
+	for _, contract := range c.AllowedContractEntries(chainID) {
+		addresses = append(addresses, contract.Address)
+	}
+	return addresses
+}
+
+func parseChainScoped(entry string) (chainID int64, value string, err error) {
+	scope, value, found := strings.Cut(entry, ":")
+	if !found {
+		return 0, entry, nil
+	}
+	chainID, err = strconv.ParseInt(strings.TrimSpace(scope), 10, 64)
+	if err != nil || chainID <= 0 {
+		return 0, "", fmt.Errorf("invalid chain ID %q", scope)
+	}
+	return chainID, strings.TrimSpace(value), nil
+}
@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nekowawolf/aicraft-bot/config"
+)
+
+const profiles = `default_profile: testnet
+profiles:
+  testnet:
+    rpc_url: https://testnet.example
+    candidate_id: profile-candidate
+    feed_amount: 2
+    workers: 3
+  mainnet:
+    rpc_url: https://mainnet.example
+    candidate_id: mainnet-candidate
+    feed_amount: 7
+`
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aicraft.yaml")
+	if err := os.WriteFile(path, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		rpc   string
+		cand  string
+		feed  int
+		works int
+	}{
+		{"default profile", nil, nil, "https://testnet.example", "profile-candidate", 2, 3},
+		{"profile flag", nil, []string{"-profile", "mainnet"}, "https://mainnet.example", "mainnet-candidate", 7, 4},
+		{"profile env", map[string]string{"AICRAFT_PROFILE": "mainnet"}, nil, "https://mainnet.example", "mainnet-candidate", 7, 4},
+		{"env over profile", map[string]string{"FEED_AMOUNT": "5", "CANDIDATE_ID": "env-candidate"}, nil, "https://testnet.example", "env-candidate", 5, 3},
+		{"flag over env", map[string]string{"FEED_AMOUNT": "5", "CANDIDATE_ID": "env-candidate"}, []string{"-feed-amount", "9"}, "https://testnet.example", "env-candidate", 9, 3},
+		{"flag over profile", nil, []string{"-rpc-url", "https://flag.example", "-workers", "8"}, "https://flag.example", "profile-candidate", 2, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"AICRAFT_CONFIG", "AICRAFT_PROFILE", "RPC_URL", "CANDIDATE_ID", "FEED_AMOUNT", "WORKERS"} {
+				t.Setenv(key, "")
+				os.Unsetenv(key)
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags := config.RegisterFlags(fs)
+			if err := fs.Parse(append([]string{"-config", path}, tt.args...)); err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			cfg, err := config.LoadConfig(config.Options{Flags: flags, SkipValidation: true})
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.RPCURL != tt.rpc || cfg.CandidateID != tt.cand || cfg.FeedAmount != tt.feed || cfg.Workers != tt.works {
+				t.Fatalf("rpc=%s candidate=%s feed=%d workers=%d; want %s %s %d %d",
+					cfg.RPCURL, cfg.CandidateID, cfg.FeedAmount, cfg.Workers, tt.rpc, tt.cand, tt.feed, tt.works)
+			}
+		})
+	}
+}
+
+func TestLoadConfigUnknownProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aicraft.yaml")
+	if err := os.WriteFile(path, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := config.LoadConfig(config.Options{File: path, Profile: "devnet", SkipValidation: true}); err == nil {
+		t.Fatal("LoadConfig succeeded with an unknown profile")
+	}
+}
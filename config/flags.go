@@ -0,0 +1,129 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+type Flags struct {
+	File    string
+	Profile string
+
+	fs               *flag.FlagSet
+	values           Config
+	allowedContracts string
+	webhookURLs      string
+}
+
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+	fs.StringVar(&f.File, "config", "", "path to the YAML config file (default "+DefaultConfigFile+" if present)")
+	fs.StringVar(&f.Profile, "profile", "", "config file profile to use")
+	fs.StringVar(&f.values.RPCURL, "rpc-url", "", "RPC endpoint URL")
+	fs.Int64Var(&f.values.ChainID, "chain-id", 0, "chain ID")
+	fs.StringVar(&f.values.APIBaseURL, "api-url", "", "AICraft API base URL")
+	fs.StringVar(&f.values.WalletID, "wallet-id", "", "AICraft wallet ID")
+	fs.StringVar(&f.values.CandidateID, "candidate", "", "candidate ID to vote for")
+	fs.StringVar(&f.values.TargetCountryID, "country", "", "target country ID")
+	fs.IntVar(&f.values.FeedAmount, "feed-amount", 0, "feed amount per vote")
+	fs.IntVar(&f.values.MaxAttempts, "max-attempts", 0, "maximum attempts for retried API calls")
+	fs.IntVar(&f.values.DelaySeconds, "delay", 0, "delay in seconds between retries")
+	fs.IntVar(&f.values.Workers, "workers", 0, "number of wallets processed concurrently")
+	fs.IntVar(&f.values.VotesPerWallet, "votes-per-wallet", 0, "votes to submit from each wallet, in order")
+	fs.Float64Var(&f.values.RPCRateLimit, "rpc-rate-limit", 0, "RPC requests per second shared by all workers (0 disables)")
+	fs.IntVar(&f.values.RPCBurst, "rpc-burst", 0, "RPC rate limiter burst size")
+	fs.Float64Var(&f.values.APIRateLimit, "api-rate-limit", 0, "API requests per second per host (0 disables)")
+	fs.IntVar(&f.values.APIBurst, "api-burst", 0, "API rate limiter burst size")
+	fs.IntVar(&f.values.APIMaxRetries, "api-max-retries", 0, "retries after a 429 response from the API")
+	fs.IntVar(&f.values.IntervalSeconds, "interval", 0, "seconds between voting rounds; 0 runs a single round and exits")
+	fs.StringVar(&f.values.HTTPAddr, "http-addr", "", "address to serve /metrics, /healthz, /readyz and /status on, e.g. :9090 (empty disables)")
+	fs.StringVar(&f.values.HistoryFile, "history-file", "", "path to the vote history file")
+	fs.StringVar(&f.values.CheckpointFile, "checkpoint-file", "", "where unconfirmed orders are saved on shutdown for the resume command")
+	fs.IntVar(&f.values.GraceSeconds, "grace", 0, "seconds in-flight votes may keep running after SIGINT/SIGTERM")
+	fs.StringVar(&f.allowedContracts, "allowed-contracts", "", "comma-separated contract addresses orders may pay, as [<chainID>:]<address>[=<code hash>]")
+	fs.StringVar(&f.values.IntegritySigner, "integrity-signer", "", "address that must have signed each order's integrity signature")
+	fs.StringVar(&f.values.SignInFormat, "sign-in-format", "", "how to sign the API sign-in message: personal_sign, eip712 or siwe")
+	fs.StringVar(&f.values.SignInDomain, "sign-in-domain", "", "domain SIWE sign-in messages must name (defaults to the API host)")
+	fs.StringVar(&f.webhookURLs, "webhook-urls", "", "comma-separated webhook URLs to notify about votes")
+	fs.StringVar(&f.values.LowBalance, "low-balance", "", "notify when a wallet balance drops below this many native tokens")
+	fs.StringVar(&f.values.BudgetWalletDaily, "budget-wallet-daily", "", "maximum fees per wallet per UTC day, in native tokens")
+	fs.StringVar(&f.values.BudgetWalletTotal, "budget-wallet-total", "", "maximum fees per wallet overall, in native tokens")
+	fs.StringVar(&f.values.BudgetGlobalDaily, "budget-global-daily", "", "maximum fees across all wallets per UTC day, in native tokens")
+	fs.StringVar(&f.values.BudgetGlobalTotal, "budget-global-total", "", "maximum fees across all wallets overall, in native tokens")
+	fs.StringVar(&f.values.LogLevel, "log-level", "", "log level: debug, info, warn or error")
+	fs.StringVar(&f.values.LogFormat, "log-format", "", "log format: text or json")
+	return f
+}
+
+func (f *Flags) apply(cfg *Config) {
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "rpc-url":
+			cfg.RPCURL = f.values.RPCURL
+		case "chain-id":
+			cfg.ChainID = f.values.ChainID
+		case "api-url":
+			cfg.APIBaseURL = f.values.APIBaseURL
+		case "wallet-id":
+			cfg.WalletID = f.values.WalletID
+		case "candidate":
+			cfg.CandidateID = f.values.CandidateID
+		case "country":
+			cfg.TargetCountryID = f.values.TargetCountryID
+		case "feed-amount":
+			cfg.FeedAmount = f.values.FeedAmount
+		case "max-attempts":
+			cfg.MaxAttempts = f.values.MaxAttempts
+		case "delay":
+			cfg.DelaySeconds = f.values.DelaySeconds
+		case "workers":
+			cfg.Workers = f.values.Workers
+		case "votes-per-wallet":
+			cfg.VotesPerWallet = f.values.VotesPerWallet
+		case "rpc-rate-limit":
+			cfg.RPCRateLimit = f.values.RPCRateLimit
+		case "rpc-burst":
+			cfg.RPCBurst = f.values.RPCBurst
+		case "api-rate-limit":
+			cfg.APIRateLimit = f.values.APIRateLimit
+		case "api-burst":
+			cfg.APIBurst = f.values.APIBurst
+		case "api-max-retries":
+			cfg.APIMaxRetries = f.values.APIMaxRetries
+		case "interval":
+			cfg.IntervalSeconds = f.values.IntervalSeconds
+		case "http-addr":
+			cfg.HTTPAddr = f.values.HTTPAddr
+		case "history-file":
+			cfg.HistoryFile = f.values.HistoryFile
+		case "checkpoint-file":
+			cfg.CheckpointFile = f.values.CheckpointFile
+		case "grace":
+			cfg.GraceSeconds = f.values.GraceSeconds
+		case "allowed-contracts":
+			cfg.AllowedContracts = strings.Split(f.allowedContracts, ",")
+		case "integrity-signer":
+			cfg.IntegritySigner = f.values.IntegritySigner
+		case "sign-in-format":
+			cfg.SignInFormat = f.values.SignInFormat
+		case "sign-in-domain":
+			cfg.SignInDomain = f.values.SignInDomain
+		case "webhook-urls":
+			cfg.WebhookURLs = strings.Split(f.webhookURLs, ",")
+		case "low-balance":
+			cfg.LowBalance = f.values.LowBalance
+		case "budget-wallet-daily":
+			cfg.BudgetWalletDaily = f.values.BudgetWalletDaily
+		case "budget-wallet-total":
+			cfg.BudgetWalletTotal = f.values.BudgetWalletTotal
+		case "budget-global-daily":
+			cfg.BudgetGlobalDaily = f.values.BudgetGlobalDaily
+		case "budget-global-total":
+			cfg.BudgetGlobalTotal = f.values.BudgetGlobalTotal
+		case "log-level":
+			cfg.LogLevel = f.values.LogLevel
+		case "log-format":
+			cfg.LogFormat = f.values.LogFormat
+		}
+	})
+}
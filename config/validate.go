@@ -0,0 +1,232 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const MaxFeedAmount = 1000
+
+var (
+	objectIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	amountPattern   = regexp.MustCompile(`^[0-9]*(\.[0-9]{1,18})?$`)
+)
+
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid configuration: " + e.Problems[0]
+	}
+	return fmt.Sprintf("invalid configuration (%d problems):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+func (e *ValidationError) add(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+func (e *ValidationError) err() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate performs all offline checks and reports every problem at once.
+func (c *Config) Validate() error {
+	problems := &ValidationError{}
+	c.validateStatic(problems)
+	return problems.err()
+}
+
+// ValidateWithRPC runs Validate and additionally checks CHAIN_ID against the chain
+// reported by RPC_URL via eth_chainId.
+func (c *Config) ValidateWithRPC(ctx context.Context) error {
+	problems := &ValidationError{}
+	c.validateStatic(problems)
+
+	if isValidRPCURL(c.RPCURL) {
+		remote, err := FetchChainID(ctx, c.RPCURL)
+		if err != nil {
+			problems.add("RPC_URL: could not query eth_chainId from %s: %v", c.RPCURL, err)
+		} else if remote != c.ChainID {
+			problems.add("CHAIN_ID: configured %d but %s reports chain %d; set CHAIN_ID=%d or use the matching RPC_URL", c.ChainID, c.RPCURL, remote, remote)
+		}
+	}
+
+	return problems.err()
+}
+
+func FetchChainID(ctx context.Context, rpcURL string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to RPC: %v", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return chainID.Int64(), nil
+}
+
+func (c *Config) validateStatic(problems *ValidationError) {
+	if c.PrivateKey == "" && len(c.PrivateKeys) == 0 {
+		problems.add("PRIVATE_KEY or PRIVATE_KEYS is required")
+	}
+	if c.PrivateKey != "" && !isValidPrivateKey(c.PrivateKey) {
+		problems.add("PRIVATE_KEY is not a valid secp256k1 key (expected 64 hex characters, optionally 0x-prefixed)")
+	}
+	for i, key := range c.PrivateKeys {
+		if !isValidPrivateKey(key) {
+			problems.add("PRIVATE_KEYS entry %d is not a valid secp256k1 key (expected 64 hex characters, optionally 0x-prefixed)", i+1)
+		}
+	}
+
+	if c.WalletID == "" {
+		problems.add("WALLET_ID is required")
+	}
+	if c.TargetCountryID == "" {
+		problems.add("TARGET_COUNTRY_ID is required")
+	}
+
+	if c.CandidateID == "" {
+		problems.add("CANDIDATE_ID is required")
+	} else if !objectIDPattern.MatchString(c.CandidateID) {
+		problems.add("CANDIDATE_ID %q is not a valid ObjectID (expected 24 hex characters)", c.CandidateID)
+	}
+
+	if !isValidRPCURL(c.RPCURL) {
+		problems.add("RPC_URL %q must be an http(s) or ws(s) URL with a host", c.RPCURL)
+	}
+	if !isValidURL(c.APIBaseURL, "http", "https") {
+		problems.add("API_BASE_URL %q must be an http(s) URL with a host", c.APIBaseURL)
+	}
+
+	if c.ChainID <= 0 {
+		problems.add("CHAIN_ID must be positive, got %d", c.ChainID)
+	}
+	if c.FeedAmount <= 0 || c.FeedAmount > MaxFeedAmount {
+		problems.add("FEED_AMOUNT must be between 1 and %d, got %d", MaxFeedAmount, c.FeedAmount)
+	}
+	if c.MaxAttempts < 1 {
+		problems.add("MAX_ATTEMPTS must be at least 1, got %d", c.MaxAttempts)
+	}
+	if c.DelaySeconds < 0 {
+		problems.add("DELAY_SECONDS must not be negative, got %d", c.DelaySeconds)
+	}
+	if c.Workers < 1 {
+		problems.add("WORKERS must be at least 1, got %d", c.Workers)
+	}
+	if c.IntervalSeconds < 0 {
+		problems.add("INTERVAL_SECONDS must not be negative (0 runs once), got %d", c.IntervalSeconds)
+	}
+	if c.HTTPAddr != "" {
+		if _, _, err := net.SplitHostPort(c.HTTPAddr); err != nil {
+			problems.add("HTTP_ADDR %q is not a valid listen address (expected host:port or :port)", c.HTTPAddr)
+		}
+	}
+	if c.GraceSeconds < 0 {
+		problems.add("SHUTDOWN_GRACE_SECONDS must not be negative, got %d", c.GraceSeconds)
+	}
+	if c.VotesPerWallet < 1 {
+		problems.add("VOTES_PER_WALLET must be at least 1, got %d", c.VotesPerWallet)
+	}
+	if c.RPCRateLimit < 0 {
+		problems.add("RPC_RATE_LIMIT must not be negative (0 disables limiting), got %g", c.RPCRateLimit)
+	}
+	if c.RPCBurst < 1 {
+		problems.add("RPC_BURST must be at least 1, got %d", c.RPCBurst)
+	}
+	if c.APIRateLimit < 0 {
+		problems.add("API_RATE_LIMIT must not be negative (0 disables limiting), got %g", c.APIRateLimit)
+	}
+	if c.APIBurst < 1 {
+		problems.add("API_BURST must be at least 1, got %d", c.APIBurst)
+	}
+	for _, budget := range []struct{ name, value string }{
+		{"BUDGET_WALLET_DAILY", c.BudgetWalletDaily},
+		{"BUDGET_WALLET_TOTAL", c.BudgetWalletTotal},
+		{"BUDGET_GLOBAL_DAILY", c.BudgetGlobalDaily},
+		{"BUDGET_GLOBAL_TOTAL", c.BudgetGlobalTotal},
+	} {
+		if v := strings.TrimSpace(budget.value); v != "" && (v == "." || !amountPattern.MatchString(v)) {
+			problems.add("%s %q must be a native token amount such as 0.5 (leave empty for no limit)", budget.name, budget.value)
+		}
+	}
+	if v := strings.TrimSpace(c.LowBalance); v != "" && (v == "." || !amountPattern.MatchString(v)) {
+		problems.add("LOW_BALANCE %q must be a native token amount such as 0.5 (leave empty to disable)", c.LowBalance)
+	}
+	for i, entry := range c.AllowedContracts {
+		contract, err := ParseAllowedContract(entry)
+		if err != nil || !common.IsHexAddress(contract.Address) {
+			problems.add("ALLOWED_CONTRACTS entry %d %q must be an address, optionally prefixed with a chain ID as in 10143:0x...", i+1, entry)
+			continue
+		}
+		if contract.CodeHash != "" && !isHexHash(contract.CodeHash) {
+			problems.add("ALLOWED_CONTRACTS entry %d pins code hash %q, which must be 0x followed by 64 hex digits", i+1, contract.CodeHash)
+		}
+	}
+	if len(c.AllowedContracts) > 0 && len(c.AllowedContractEntries(c.ChainID)) == 0 {
+		problems.add("ALLOWED_CONTRACTS has no entry for CHAIN_ID %d, so every order would be refused; add one such as %d:0x...", c.ChainID, c.ChainID)
+	}
+	switch c.SignInFormat {
+	case "personal_sign", "eip712", "siwe":
+	default:
+		problems.add("SIGN_IN_FORMAT must be personal_sign, eip712 or siwe, got %q", c.SignInFormat)
+	}
+	if c.IntegritySigner != "" && !common.IsHexAddress(c.IntegritySigner) {
+		problems.add("INTEGRITY_SIGNER %q must be an address (leave empty to skip integrity signature checks)", c.IntegritySigner)
+	}
+	for i, u := range c.WebhookURLs {
+		if !isValidURL(u, "http", "https") {
+			problems.add("WEBHOOK_URLS entry %d %q must be an http(s) URL with a host", i+1, u)
+		}
+	}
+	if c.APIMaxRetries < 0 {
+		problems.add("API_MAX_RETRIES must not be negative, got %d", c.APIMaxRetries)
+	}
+}
+
+func isHexHash(s string) bool {
+	b, err := hexutil.Decode(s)
+	return err == nil && len(b) == common.HashLength
+}
+
+func isValidPrivateKey(key string) bool {
+	_, err := crypto.HexToECDSA(strings.TrimPrefix(key, "0x"))
+	return err == nil
+}
+
+func isValidRPCURL(raw string) bool {
+	return isValidURL(raw, "http", "https", "ws", "wss")
+}
+
+func isValidURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
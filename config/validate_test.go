@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nekowawolf/aicraft-bot/config"
+)
+
+func validConfig() *config.Config {
+	cfg := config.Default()
+	cfg.PrivateKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	cfg.WalletID = "wallet"
+	cfg.TargetCountryID = "VN"
+	cfg.CandidateID = "678dbb6579af53b8da5ddf3d"
+	return cfg
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	cfg := validConfig()
+	cfg.PrivateKey = "not-a-key"
+	cfg.WalletID = ""
+	cfg.CandidateID = "candidate"
+	cfg.FeedAmount = config.MaxFeedAmount + 1
+
+	var verr *config.ValidationError
+	if err := cfg.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Validate = %v, want a ValidationError", err)
+	}
+	want := []string{"PRIVATE_KEY", "WALLET_ID", "CANDIDATE_ID", "FEED_AMOUNT"}
+	if len(verr.Problems) != len(want) {
+		t.Fatalf("problems = %q, want one for each of %v", verr.Problems, want)
+	}
+	for i, field := range want {
+		if !strings.HasPrefix(verr.Problems[i], field) {
+			t.Errorf("problem %d = %q, want it about %s", i, verr.Problems[i], field)
+		}
+	}
+}
+
+func TestValidateWithRPCReportsChainIDMismatch(t *testing.T) {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x1"})
+	}))
+	defer rpc.Close()
+
+	cfg := validConfig()
+	cfg.RPCURL = rpc.URL
+	err := cfg.ValidateWithRPC(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "CHAIN_ID: configured 10143") || !strings.Contains(err.Error(), "reports chain 1") {
+		t.Fatalf("ValidateWithRPC = %v, want a chain ID mismatch", err)
+	}
+
+	cfg.ChainID = 1
+	if err := cfg.ValidateWithRPC(context.Background()); err != nil {
+		t.Fatalf("ValidateWithRPC with matching chain: %v", err)
+	}
+}
+
+func TestValidateRefusesAllowlistWithoutConfiguredChain(t *testing.T) {
+	cfg := validConfig()
+	cfg.AllowedContracts = []string{"1:0x0000000000000000000000000000000000000AC1"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "no entry for CHAIN_ID 10143") {
+		t.Fatalf("Validate = %v, want the missing chain reported", err)
+	}
+
+	cfg.AllowedContracts = append(cfg.AllowedContracts, "0x0000000000000000000000000000000000000AC1")
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate with an unscoped entry: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package history
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+)
+
+const DefaultFile = "aicraft-history.jsonl"
+
+type Record struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Wallet      string    `json:"wallet"`
+	CandidateID string    `json:"candidateId"`
+	CountryID   string    `json:"countryId"`
+	FeedAmount  int       `json:"feedAmount"`
+	OrderID     string    `json:"orderId,omitempty"`
+	TxHash      string    `json:"txHash,omitempty"`
+	BlockNumber uint64    `json:"blockNumber,omitempty"`
+	GasUsed     uint64    `json:"gasUsed,omitempty"`
+	FeePaid     string    `json:"feePaid,omitempty"`
+	Status      string    `json:"status"`
+	Stage       string    `json:"stage,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+func FromResult(r pipeline.VoteResult, at time.Time) Record {
+	return Record{
+		Timestamp:   at.UTC(),
+		Wallet:      r.WalletAddress,
+		CandidateID: r.CandidateID,
+		CountryID:   r.TargetCountryID,
+		FeedAmount:  r.FeedAmount,
+		OrderID:     r.OrderID,
+		TxHash:      r.TxHash,
+		BlockNumber: r.BlockNumber,
+		GasUsed:     r.GasUsed,
+		FeePaid:     r.EffectiveFee,
+		Status:      r.Status,
+		Stage:       string(r.Stage),
+		Error:       r.Error,
+	}
+}
+
+type Filter struct {
+	Wallet string
+	Status string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+func (f Filter) match(r Record) bool {
+	if f.Wallet != "" && !strings.EqualFold(f.Wallet, r.Wallet) {
+		return false
+	}
+	if f.Status != "" && !strings.EqualFold(f.Status, r.Status) {
+		return false
+	}
+	if !f.Since.IsZero() && r.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !r.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Store is an append-only JSON Lines file with one Record per line.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+func Open(path string) (*Store, error) {
+	if path == "" {
+		path = DefaultFile
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create history directory: %v", err)
+		}
+	}
+	return &Store{path: path}, nil
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) Append(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history record: %v", err)
+	}
+	return nil
+}
+
+// List returns matching records oldest first. With a Limit, only the most recent matches are kept.
+func (s *Store) List(filter Filter) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, fmt.Errorf("corrupt history record on line %d: %v", lineNo, err)
+		}
+		if filter.match(r) {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records, nil
+}
+
+var csvHeader = []string{
+	"timestamp", "wallet", "candidate_id", "country_id", "feed_amount", "order_id",
+	"tx_hash", "block_number", "gas_used", "fee_paid", "status", "stage", "error",
+}
+
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			r.Timestamp.Format(time.RFC3339),
+			r.Wallet,
+			r.CandidateID,
+			r.CountryID,
+			strconv.Itoa(r.FeedAmount),
+			r.OrderID,
+			r.TxHash,
+			formatUint(r.BlockNumber),
+			formatUint(r.GasUsed),
+			r.FeePaid,
+			r.Status,
+			r.Stage,
+			r.Error,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatUint(v uint64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatUint(v, 10)
+}
@@ -0,0 +1,92 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreAppendAndFilter(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Timestamp: day, Wallet: "0xAAA", Status: "confirmed", TxHash: "0x1", FeePaid: "100"},
+		{Timestamp: day.Add(24 * time.Hour), Wallet: "0xBBB", Status: "failed", Error: "boom"},
+		{Timestamp: day.Add(48 * time.Hour), Wallet: "0xaaa", Status: "confirmed", TxHash: "0x2"},
+	}
+	for _, r := range records {
+		if err := store.Append(r); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"all", Filter{}, 3},
+		{"wallet is case-insensitive", Filter{Wallet: "0xaaa"}, 2},
+		{"status", Filter{Status: "failed"}, 1},
+		{"since", Filter{Since: day.Add(time.Hour)}, 2},
+		{"until is exclusive", Filter{Until: day.Add(24 * time.Hour)}, 1},
+		{"limit keeps newest", Filter{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		got, err := store.List(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: List: %v", tt.name, err)
+		}
+		if len(got) != tt.want {
+			t.Fatalf("%s: got %d records, want %d", tt.name, len(got), tt.want)
+		}
+	}
+
+	latest, _ := store.List(Filter{Limit: 1})
+	if latest[0].TxHash != "0x2" {
+		t.Fatalf("Limit kept %+v, want the newest record", latest[0])
+	}
+}
+
+func TestListMissingFile(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	records, err := store.List(Filter{})
+	if err != nil || len(records) != 0 {
+		t.Fatalf("List on missing file = %v, %v; want empty", records, err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Record{{
+		Timestamp:   time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Wallet:      "0xAAA",
+		FeedAmount:  2,
+		BlockNumber: 42,
+		Status:      "failed",
+		Error:       `says "no", twice`,
+	}})
+	if err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV back: %v", err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(csvHeader) {
+		t.Fatalf("rows = %v", rows)
+	}
+	if rows[1][0] != "2026-03-01T12:00:00Z" || rows[1][7] != "42" || rows[1][12] != `says "no", twice` {
+		t.Fatalf("unexpected row %v", rows[1])
+	}
+}
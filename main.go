@@ -1,109 +1,307 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/joho/godotenv"
 	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/checkpoint"
 	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/history"
+	"github.com/nekowawolf/aicraft-bot/logging"
+	"github.com/nekowawolf/aicraft-bot/metrics"
+	"github.com/nekowawolf/aicraft-bot/notify"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/spend"
+	"github.com/nekowawolf/aicraft-bot/status"
 	"github.com/nekowawolf/aicraft-bot/wallet"
 )
 
 func main() {
 	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: No .env file found, using system environment variables")
+		slog.Warn("no .env file found, using system environment variables")
 	}
 
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("❌ Failed to load config: %v", err)
+	args := os.Args[1:]
+	cmd := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "run":
+		runCommand(args)
+	case "config":
+		configCommand(args)
+	case "history":
+		historyCommand(args)
+	case "spend":
+		spendCommand(args)
+	case "resume":
+		resumeCommand(args)
+	case "tx":
+		txCommand(args)
+	case "mock-api":
+		mockAPICommand(args)
+	default:
+		fatal("❌ Unknown command", fmt.Errorf("%q (expected run, resume, tx, config, history, spend or mock-api)", cmd))
 	}
+}
 
-	printConfig(cfg)
+type commandFlags struct {
+	fs     *flag.FlagSet
+	output *string
+	config *config.Flags
+}
 
-	wallet, err := wallet.NewWallet(cfg.PrivateKey)
+func newCommandFlags(name string) *commandFlags {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	return &commandFlags{
+		fs:     fs,
+		output: fs.String("output", "text", "output format: text or json"),
+		config: config.RegisterFlags(fs),
+	}
+}
+
+func (f *commandFlags) parse(args []string) *printer {
+	f.fs.Parse(args)
+	out, err := newPrinter(os.Stdout, *f.output)
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize wallet: %v", err)
+		fatal("❌ Invalid output format", err)
 	}
-	fmt.Printf("🔑 Wallet address: %s\n", wallet.GetAddress())
+	return out
+}
 
-	token, err := api.WalletSignIn(wallet)
+func (f *commandFlags) loadConfig(skipValidation bool) (*config.Config, error) {
+	cfg, err := config.LoadConfig(config.Options{Flags: f.config, SkipValidation: skipValidation})
 	if err != nil {
-		log.Fatalf("❌ Failed to authenticate: %v", err)
-	}
-	fmt.Printf("🔑 Authentication successful\n")
-
-	fmt.Printf("🗳️ Creating vote order for candidate %s...\n", cfg.CandidateID)
-	order, err := api.CreateVoteOrder(
-		token,
-		cfg.CandidateID,
-		cfg.GetChainIDString(),
-		cfg.TargetCountryID,
-		cfg.RPCURL,
-		cfg.WalletID,
-		cfg.FeedAmount,
-	)
+		return nil, err
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
-		log.Fatalf("❌ Failed to create vote order: %v", err)
-	}
-	printOrderDetails(order)
-
-	fmt.Printf("⛓ Creating blockchain transaction...\n")
-	txHash, err := wallet.CreateVoteTransaction(
-		cfg.RPCURL,
-		order.Data.Payment.ContractAddress,
-		cfg.CandidateID,
-		cfg.FeedAmount,
-		cfg.ChainID,
-		order.Data.Order.ID,
-		order.Data.Payment.Params.RequestData,
-		order.Data.Payment.Params.UserHashedMessage,
-		order.Data.Payment.Params.IntegritySignature,
-	)
+		return nil, fmt.Errorf("failed to initialize logger: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	return cfg, nil
+}
+
+func runCommand(args []string) {
+	flags := newCommandFlags("run")
+	record := flags.fs.String("record", "", "record API traffic (redacted) to this cassette file")
+	replay := flags.fs.String("replay", "", "serve API responses from this cassette file instead of the network")
+	out := flags.parse(args)
+
+	cfg, err := flags.loadConfig(false)
 	if err != nil {
-		log.Fatalf("❌ Failed to create vote transaction: %v", err)
+		out.fail(&pipeline.VoteResult{Status: pipeline.StatusFailed}, "❌ Failed to load config", err)
 	}
-	fmt.Printf("📝 Transaction hash: %s\n", txHash)
 
-	fmt.Printf("⏳ Waiting for transaction confirmation (timeout: 5 minutes)...\n")
-	receipt, err := wallet.WaitForTransactionReceipt(cfg.RPCURL, txHash)
+	out.printConfig(cfg)
+	if len(cfg.AllowedContracts) == 0 {
+		slog.Warn("ALLOWED_CONTRACTS is not set; orders may pay any contract", "chain_id", cfg.ChainID)
+	}
+	if cfg.IntegritySigner == "" {
+		slog.Warn("INTEGRITY_SIGNER is not set; order integrity signatures are not verified")
+	}
+
+	store, err := history.Open(cfg.HistoryFile)
+	if err != nil {
+		fatal("❌ Failed to open vote history", err)
+	}
+	tracker, err := newSpendTracker(cfg, store)
 	if err != nil {
-		log.Fatalf("❌ Failed to get transaction receipt: %v", err)
+		fatal("❌ Failed to load spend history", err)
 	}
+	checkpoints := checkpoint.Open(cfg.CheckpointFile)
+	notifier := notify.New(cfg.WebhookURLs, cfg.WebhookSecret)
+	defer notifier.Close()
+	if notifier.LowBalance, err = spend.ParseAmount(cfg.LowBalance); err != nil {
+		fatal("❌ Invalid LOW_BALANCE", err)
+	}
+
+	m := metrics.New()
+	board := status.NewBoard(status.DefaultResults)
+	limits := wallet.NewRateLimits(cfg.RPCRateLimit, cfg.RPCBurst)
+	apiOptions := api.Options{
+		RequestsPerSecond: cfg.APIRateLimit,
+		Burst:             cfg.APIBurst,
+		MaxRetries:        cfg.APIMaxRetries,
+		SignInFormat:      api.SignInFormat(cfg.SignInFormat),
+		SignInDomain:      cfg.SignInDomain,
+		ChainID:           cfg.ChainID,
+		Observe:           m.ObserveAPI(),
+	}
+	switch {
+	case *record != "" && *replay != "":
+		fatal("❌ Invalid flags", errors.New("-record and -replay are mutually exclusive"))
+	case *record != "":
+		apiOptions.Transport = api.NewRecordingTransport(nil, *record)
+	case *replay != "":
+		cassette, err := api.LoadCassette(*replay)
+		if err != nil {
+			fatal("❌ Failed to load cassette", err)
+		}
+		apiOptions.Transport = api.NewReplayTransport(cassette)
+	}
+	client := api.NewClientWithOptions(cfg.APIBaseURL, apiOptions)
+
+	policy := newContractPolicy(cfg)
+	var work []pipeline.WalletJobs
+	for i, key := range cfg.Keys() {
+		w, err := wallet.NewWallet(key)
+		if err != nil {
+			out.fail(&pipeline.VoteResult{Status: pipeline.StatusFailed}, "❌ Failed to initialize wallet", fmt.Errorf("key %d: %v", i+1, err))
+		}
+		defer w.Close()
+		w.SetDialer(limits.Dialer(wallet.ObserveDialer(nil, m.ObserveRPC())))
+		w.SetContractPolicy(policy)
+		out.Printf("🔑 Wallet address: %s\n", w.GetAddress())
+		board.Track(w.GetAddress())
+
+		jobs := make([]pipeline.VoteJob, cfg.VotesPerWallet)
+		for j := range jobs {
+			jobs[j] = pipeline.JobFromConfig(cfg)
+		}
+		work = append(work, pipeline.WalletJobs{Wallet: w, Jobs: jobs})
+	}
+	out.prefixWallet = len(work) > 1
+
+	var checker *status.Checker
+	if cfg.HTTPAddr != "" {
+		wallets := make([]*wallet.Wallet, len(work))
+		for i, wj := range work {
+			wallets[i] = wj.Wallet
+		}
+		checker = &status.Checker{
+			Wallets: wallets,
+			API:     client,
+			RPCURL:  cfg.RPCURL,
+			ChainID: cfg.ChainID,
+		}
+	}
+
+	pool := &pipeline.Pool{
+		Workers: cfg.Workers,
+		NewRunner: func(w *wallet.Wallet) *pipeline.Runner {
+			runner := pipeline.NewRunner(w, client)
+			runner.MaxAttempts = cfg.MaxAttempts
+			runner.RetryDelay = time.Duration(cfg.DelaySeconds) * time.Second
+			runner.Budget = tracker
+			runner.Orders = checkpoints
+			runner.OnEvent(out.progress)
+			runner.OnEvent(m.ObserveEvent)
+			runner.OnEvent(board.ObserveEvent)
+			runner.OnEvent(notifier.ObserveEvent)
+			runner.OnEvent(checkpoints.ObserveEvent)
+			if checker != nil {
+				runner.OnEvent(checker.ObserveEvent)
+			}
+			return runner
+		},
+	}
+	pool.OnResult = func(result pipeline.VoteResult) {
+		m.ObserveResult(result)
+		board.ObserveResult(result)
+		notifier.ObserveResult(result)
+		if err := store.Append(history.FromResult(result, time.Now())); err != nil {
+			slog.Warn("failed to record vote history", "error", err)
+		}
+		checkpoints.ObserveResult(result)
+		if checkpoint.Pending(result) {
+			saveCheckpoint(checkpoints, checkpoint.FromResult(pipeline.JobFromConfig(cfg), result, time.Now()))
+		}
+	}
+
+	if cfg.HTTPAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m.Handler())
+		statusServer := &status.Server{Board: board, Checker: checker}
+		statusServer.Register(mux)
+		server := serveHTTP(cfg.HTTPAddr, mux)
+		defer server.Close()
+	}
+
+	stopping, ctx := handleShutdown(time.Duration(cfg.GraceSeconds) * time.Second)
+	pool.Stopping = stopping
 
-	if receipt.Status != 1 {
-		log.Fatalf("❌ Transaction failed: %s", txHash)
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	for {
+		summary := pool.Run(ctx, work)
+		if interval <= 0 || isClosed(stopping) {
+			notifier.Close()
+			reportRun(out, summary)
+			return
+		}
+		out.printSummary(summary)
+		out.result(summary)
+		out.Printf("⏳ Next round in %s\n", interval)
+		select {
+		case <-time.After(interval):
+		case <-stopping:
+			return
+		}
 	}
-	fmt.Printf("✅ Transaction confirmed in block %d\n", receipt.BlockNumber)
+}
 
-	fmt.Printf("✅ Confirming vote order...\n")
-	if err := api.ConfirmVoteOrder(token, order.Data.Order.ID, txHash); err != nil {
-		log.Fatalf("❌ Failed to confirm vote order: %v", err)
+// newContractPolicy lets the wallets sign only for the contracts that
+// ALLOWED_CONTRACTS permits. Unscoped entries apply to the configured chain,
+// and chains without any entry are refused once ALLOWED_CONTRACTS is set.
+func newContractPolicy(cfg *config.Config) *wallet.ContractPolicy {
+	policy := wallet.NewContractPolicy()
+	for _, entry := range cfg.AllowedContracts {
+		contract, err := config.ParseAllowedContract(entry)
+		if err != nil {
+			continue
+		}
+		chainID := contract.ChainID
+		if chainID == 0 {
+			chainID = cfg.ChainID
+		}
+		policy.Allow(chainID, common.HexToAddress(contract.Address), common.HexToHash(contract.CodeHash))
 	}
+	return policy
+}
 
-	fmt.Println("\n🎉 Vote successfully submitted!")
-	fmt.Printf("🔗 Transaction: %s\n", txHash)
-	fmt.Printf("🗳️ Candidate: %s\n", cfg.CandidateID)
-	fmt.Printf("🌎 Country: %s\n", cfg.TargetCountryID)
+func reportRun(out *printer, summary *pipeline.Summary) {
+	if len(summary.Results) == 1 {
+		result := summary.Results[0]
+		if result.Status != pipeline.StatusConfirmed {
+			out.result(&result)
+			fatal(stageFailureMessage(result.Stage), errors.New(result.Error))
+		}
+		out.result(&result)
+		return
+	}
+
+	out.printSummary(summary)
+	out.result(summary)
+	if summary.Failed > 0 {
+		fatal("❌ Some votes failed", fmt.Errorf("%d of %d votes failed", summary.Failed, len(summary.Results)))
+	}
 }
 
-func printConfig(cfg *config.Config) {
-	fmt.Println("\n⚙️ Configuration:")
-	fmt.Printf("• RPC URL: %s\n", cfg.RPCURL)
-	fmt.Printf("• Chain ID: %d\n", cfg.ChainID)
-	fmt.Printf("• Target Country ID: %s\n", cfg.TargetCountryID)
-	fmt.Printf("• Candidate ID: %s\n", cfg.CandidateID)
-	fmt.Printf("• Feed Amount: %d\n", cfg.FeedAmount)
-	fmt.Printf("• Delay Seconds: %d\n\n", cfg.DelaySeconds)
+func serveHTTP(addr string, handler http.Handler) *http.Server {
+	server := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("❌ HTTP server failed", err)
+		}
+	}()
+	slog.Info("serving HTTP endpoints", "addr", addr)
+	return server
 }
 
-func printOrderDetails(order *api.OrderResponse) {
-	fmt.Println("\n📄 Order Details:")
-	fmt.Printf("• Order ID: %s\n", order.Data.Order.ID)
-	fmt.Printf("• Status: %s\n", order.Data.Order.Status)
-	fmt.Printf("• Contract Address: %s\n", order.Data.Payment.ContractAddress)
-	fmt.Printf("• Function: %s\n", order.Data.Payment.FunctionName)
-	fmt.Printf("• Feed Amount: %d\n", order.Data.Payment.Params.FeedAmount)
-	fmt.Println()
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "aicraft"
+
+// Metrics owns a private registry so that tests and multiple bots in one
+// process do not collide on the global default registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	stages       *prometheus.CounterVec
+	stageSeconds *prometheus.HistogramVec
+	votes        *prometheus.CounterVec
+	apiSeconds   *prometheus.HistogramVec
+	rpcSeconds   *prometheus.HistogramVec
+	gasUsed      prometheus.Histogram
+	feesPaid     prometheus.Counter
+	confirmation prometheus.Histogram
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		stages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stage_total",
+			Help:      "Pipeline stages by outcome (started, completed or failed).",
+		}, []string{"stage", "outcome"}),
+		stageSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "stage_duration_seconds",
+			Help:      "Time spent in each pipeline stage.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"stage", "outcome"}),
+		votes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "votes_total",
+			Help:      "Finished votes by status and, for failures, the stage that failed.",
+		}, []string{"status", "stage"}),
+		apiSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "AICraft API request latency by endpoint and HTTP status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "code"}),
+		rpcSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_request_duration_seconds",
+			Help:      "Ethereum RPC latency by method and result.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "result"}),
+		gasUsed: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "vote_gas_used",
+			Help:      "Gas used by mined vote transactions.",
+			Buckets:   prometheus.ExponentialBuckets(25000, 1.5, 10),
+		}),
+		feesPaid: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "vote_fees_paid_gwei_total",
+			Help:      "Transaction fees paid for mined vote transactions, in gwei (float, so sub-gwei amounts may round).",
+		}),
+		confirmation: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "vote_time_to_confirmation_seconds",
+			Help:      "Time from broadcasting a vote transaction to receiving its receipt.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 10),
+		}),
+	}
+	m.registry.MustRegister(
+		m.stages,
+		m.stageSeconds,
+		m.votes,
+		m.apiSeconds,
+		m.rpcSeconds,
+		m.gasUsed,
+		m.feesPaid,
+		m.confirmation,
+	)
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveEvent is a pipeline.Hook.
+func (m *Metrics) ObserveEvent(ev pipeline.Event) {
+	stage := string(ev.Stage)
+	m.stages.WithLabelValues(stage, string(ev.Kind)).Inc()
+	if ev.Kind == pipeline.EventStarted {
+		return
+	}
+	m.stageSeconds.WithLabelValues(stage, string(ev.Kind)).Observe(ev.Elapsed.Seconds())
+
+	if ev.Stage == pipeline.StageWaitReceipt && ev.Receipt != nil {
+		m.confirmation.Observe(ev.Elapsed.Seconds())
+	}
+}
+
+func (m *Metrics) ObserveResult(result pipeline.VoteResult) {
+	stage := ""
+	if result.Status != pipeline.StatusConfirmed {
+		stage = string(result.Stage)
+	}
+	m.votes.WithLabelValues(result.Status, stage).Inc()
+
+	if result.GasUsed > 0 {
+		m.gasUsed.Observe(float64(result.GasUsed))
+	}
+	// Wei totals pass 2^53 quickly and would lose precision as a float64.
+	if fee, ok := new(big.Int).SetString(result.EffectiveFee, 10); ok {
+		gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(fee), big.NewFloat(1e9)).Float64()
+		m.feesPaid.Add(gwei)
+	}
+}
+
+func (m *Metrics) ObserveAPI() api.RequestObserver {
+	return func(endpoint string, status int, elapsed time.Duration, err error) {
+		m.apiSeconds.WithLabelValues(endpoint, strconv.Itoa(status)).Observe(elapsed.Seconds())
+	}
+}
+
+func (m *Metrics) ObserveRPC() wallet.RPCObserver {
+	return func(method string, elapsed time.Duration, err error) {
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		m.rpcSeconds.WithLabelValues(method, result).Observe(elapsed.Seconds())
+	}
+}
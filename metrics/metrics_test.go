@@ -0,0 +1,82 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/chaintest"
+	"github.com/nekowawolf/aicraft-bot/metrics"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+func TestMetricsRecordVotePipeline(t *testing.T) {
+	env := chaintest.NewEnv(t)
+	w, server := env.Wallet, env.Server
+
+	m := metrics.New()
+	w.SetDialer(wallet.ObserveDialer(env.Chain.Dialer(), m.ObserveRPC()))
+
+	opts := api.DefaultOptions()
+	opts.Observe = m.ObserveAPI()
+	runner := pipeline.NewRunner(w, api.NewClientWithOptions(server.URL, opts))
+	runner.OnEvent(m.ObserveEvent)
+
+	result, err := runner.Run(context.Background(), pipeline.VoteJob{
+		RPCURL:          "simulated://chain",
+		ChainID:         chaintest.ChainID,
+		WalletID:        "test-wallet",
+		CandidateID:     "678dbb6579af53b8da5ddf3d",
+		TargetCountryID: "VN",
+		FeedAmount:      3,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	m.ObserveResult(result)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	scrape := string(body)
+
+	for _, want := range []string{
+		`aicraft_stage_total{outcome="completed",stage="confirm-order"} 1`,
+		`aicraft_votes_total{stage="",status="confirmed"} 1`,
+		`aicraft_api_request_duration_seconds_count{code="201",endpoint="create-order"} 1`,
+		`aicraft_api_request_duration_seconds_count{code="200",endpoint="confirm-order"} 1`,
+		`aicraft_rpc_request_duration_seconds_count{method="eth_sendRawTransaction",result="ok"} 1`,
+		`aicraft_vote_gas_used_count 1`,
+		`aicraft_vote_time_to_confirmation_seconds_count 1`,
+		`aicraft_vote_fees_paid_gwei_total`,
+	} {
+		if !strings.Contains(scrape, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+	if strings.Contains(scrape, "aicraft_vote_fees_paid_gwei_total 0\n") {
+		t.Error("fees paid was not recorded")
+	}
+}
+
+func TestEndpointCollapsesOrderIDs(t *testing.T) {
+	cases := []struct {
+		method, path, want string
+	}{
+		{"GET", "/auths/wallets/sign-in/message", api.EndpointSignInMessage},
+		{"POST", "/auths/wallets/sign-in", api.EndpointSignIn},
+		{"POST", "/feeds/orders", api.EndpointCreateOrder},
+		{"GET", "/feeds/orders/6650f0c2a1b2c3d4e5f60718", api.EndpointGetOrder},
+		{"POST", "/feeds/orders/6650f0c2a1b2c3d4e5f60718/confirm", api.EndpointConfirmOrder},
+		{"GET", "/unknown", api.EndpointOther},
+	}
+	for _, c := range cases {
+		if got := api.Endpoint(c.method, c.path); got != c.want {
+			t.Errorf("Endpoint(%s %s) = %q, want %q", c.method, c.path, got, c.want)
+		}
+	}
+}
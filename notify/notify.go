@@ -0,0 +1,253 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/spend"
+)
+
+const (
+	EventVoteConfirmed   = "vote.confirmed"
+	EventVoteFailed      = "vote.failed"
+	EventLowBalance      = "balance.low"
+	EventBudgetExhausted = "budget.exhausted"
+)
+
+const (
+	SignatureHeader = "X-AICraft-Signature"
+	EventHeader     = "X-AICraft-Event"
+
+	DefaultMaxRetries = 3
+	DefaultBackoff    = time.Second
+
+	queueSize = 64
+)
+
+type Payload struct {
+	Event       string    `json:"event"`
+	Timestamp   time.Time `json:"timestamp"`
+	Wallet      string    `json:"wallet"`
+	CandidateID string    `json:"candidateId,omitempty"`
+	OrderID     string    `json:"orderId,omitempty"`
+	TxHash      string    `json:"txHash,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Stage       string    `json:"stage,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Balance     string    `json:"balance,omitempty"`
+	Threshold   string    `json:"threshold,omitempty"`
+}
+
+// Notifier delivers payloads to every webhook URL from a background
+// goroutine so that slow receivers never hold up a vote. Failed deliveries
+// are retried with exponential backoff on network errors, 429 and 5xx.
+type Notifier struct {
+	URLs       []string
+	Secret     string
+	LowBalance *big.Int
+	MaxRetries int
+	Backoff    time.Duration
+	HTTPClient *http.Client
+
+	queue chan Payload
+	done  chan struct{}
+	once  sync.Once
+
+	mu  sync.Mutex
+	low map[string]bool
+}
+
+func New(urls []string, secret string) *Notifier {
+	n := &Notifier{
+		URLs:       urls,
+		Secret:     secret,
+		MaxRetries: DefaultMaxRetries,
+		Backoff:    DefaultBackoff,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		queue:      make(chan Payload, queueSize),
+		done:       make(chan struct{}),
+		low:        make(map[string]bool),
+	}
+	go n.loop()
+	return n
+}
+
+// Notify queues p for delivery. It is a no-op when no URLs are configured,
+// and drops p rather than wait when the queue is full.
+func (n *Notifier) Notify(p Payload) {
+	if len(n.URLs) == 0 {
+		return
+	}
+	if p.Timestamp.IsZero() {
+		p.Timestamp = time.Now().UTC()
+	}
+	select {
+	case n.queue <- p:
+	default:
+		slog.Warn("webhook queue is full, dropping notification", "event", p.Event, "wallet", p.Wallet)
+	}
+}
+
+// Close waits for queued payloads to be delivered or given up on.
+func (n *Notifier) Close() {
+	n.once.Do(func() { close(n.queue) })
+	<-n.done
+}
+
+// ObserveEvent is a pipeline.Hook that reports a wallet's balance falling
+// below LowBalance, once until it recovers.
+func (n *Notifier) ObserveEvent(ev pipeline.Event) {
+	if n.LowBalance == nil || ev.Kind != pipeline.EventCompleted || ev.Health == nil || ev.Health.Balance == nil {
+		return
+	}
+	address := ev.Health.Address
+	low := ev.Health.Balance.Cmp(n.LowBalance) < 0
+
+	n.mu.Lock()
+	alreadyLow := n.low[address]
+	n.low[address] = low
+	n.mu.Unlock()
+
+	if low && !alreadyLow {
+		n.Notify(Payload{
+			Event:     EventLowBalance,
+			Wallet:    address,
+			Balance:   spend.FormatAmount(ev.Health.Balance),
+			Threshold: spend.FormatAmount(n.LowBalance),
+		})
+	}
+}
+
+// ObserveResult reports a confirmed or failed vote. Skipped votes, which were
+// never attempted because of shutdown or an earlier failure, are not sent.
+func (n *Notifier) ObserveResult(result pipeline.VoteResult) {
+	if result.Status == pipeline.StatusSkipped {
+		return
+	}
+	p := Payload{
+		Event:       EventVoteConfirmed,
+		Wallet:      result.WalletAddress,
+		CandidateID: result.CandidateID,
+		OrderID:     result.OrderID,
+		TxHash:      result.TxHash,
+		Status:      result.Status,
+		Error:       result.Error,
+	}
+	if result.Status != pipeline.StatusConfirmed {
+		p.Event = EventVoteFailed
+		p.Stage = string(result.Stage)
+		if result.Stage == pipeline.StageBudget {
+			p.Event = EventBudgetExhausted
+		}
+	}
+	n.Notify(p)
+}
+
+func (n *Notifier) loop() {
+	defer close(n.done)
+	for p := range n.queue {
+		body, err := json.Marshal(p)
+		if err != nil {
+			slog.Warn("failed to encode webhook payload", "event", p.Event, "error", err)
+			continue
+		}
+		for _, target := range n.URLs {
+			if err := n.deliver(target, p.Event, body); err != nil {
+				slog.Warn("webhook delivery failed", "host", webhookHost(target), "event", p.Event, "error", err)
+			}
+		}
+	}
+}
+
+func (n *Notifier) deliver(target, event string, body []byte) error {
+	backoff := n.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(target, event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.MaxRetries {
+			return err
+		}
+		slog.Debug("retrying webhook", "host", webhookHost(target), "event", event, "attempt", attempt+1, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) post(target, event string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.New("failed to create request: invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if n.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.Secret, body))
+	}
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		// url.Error repeats the full URL, which may carry a secret.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return true, fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+}
+
+// webhookHost returns the host of a webhook URL for logging, leaving out the
+// path and query where receivers often put a secret.
+func webhookHost(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "invalid URL"
+	}
+	return u.Host
+}
+
+// Sign returns the value of the signature header for body: "sha256=" followed
+// by the hex HMAC-SHA256 of the raw body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var ErrBadSignature = errors.New("webhook signature mismatch")
+
+// Verify is the receiver-side check of a signature header against body.
+func Verify(secret string, body []byte, signature string) error {
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !strings.HasPrefix(signature, "sha256=") {
+		return ErrBadSignature
+	}
+	want, _ := hex.DecodeString(strings.TrimPrefix(Sign(secret, body), "sha256="))
+	if !hmac.Equal(got, want) {
+		return ErrBadSignature
+	}
+	return nil
+}
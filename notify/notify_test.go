@@ -0,0 +1,206 @@
+package notify_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/notify"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+const secret = "webhook-secret"
+
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	attempts int
+	payloads []notify.Payload
+	badSigs  int
+}
+
+func newReceiver(t *testing.T, failures int) *receiver {
+	r := &receiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.attempts++
+		if r.failures > 0 {
+			r.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := notify.Verify(secret, body, req.Header.Get(notify.SignatureHeader)); err != nil {
+			r.badSigs++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var p notify.Payload
+		if err := json.Unmarshal(body, &p); err != nil || req.Header.Get(notify.EventHeader) != p.Event {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.payloads = append(r.payloads, p)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestNotifierSignsAndRetries(t *testing.T) {
+	recv := newReceiver(t, 2)
+	n := notify.New([]string{recv.URL}, secret)
+	n.Backoff = time.Millisecond
+
+	n.ObserveResult(pipeline.VoteResult{
+		WalletAddress: "0xabc",
+		CandidateID:   "678dbb6579af53b8da5ddf3d",
+		OrderID:       "order-1",
+		TxHash:        "0x01",
+		Status:        pipeline.StatusConfirmed,
+	})
+	n.ObserveResult(pipeline.VoteResult{
+		WalletAddress: "0xabc",
+		Stage:         pipeline.StageBudget,
+		Status:        pipeline.StatusFailed,
+		Error:         "fee budget exceeded",
+	})
+	n.Close()
+
+	if recv.attempts != 4 || recv.badSigs != 0 {
+		t.Fatalf("attempts = %d, bad signatures = %d; want 4 and 0", recv.attempts, recv.badSigs)
+	}
+	if len(recv.payloads) != 2 {
+		t.Fatalf("payloads = %+v, want 2", recv.payloads)
+	}
+	if p := recv.payloads[0]; p.Event != notify.EventVoteConfirmed || p.OrderID != "order-1" || p.TxHash != "0x01" {
+		t.Fatalf("first payload = %+v", p)
+	}
+	if p := recv.payloads[1]; p.Event != notify.EventBudgetExhausted || p.Error == "" {
+		t.Fatalf("second payload = %+v", p)
+	}
+}
+
+func TestNotifierGivesUpAfterMaxRetries(t *testing.T) {
+	recv := newReceiver(t, 10)
+	n := notify.New([]string{recv.URL}, secret)
+	n.Backoff = time.Millisecond
+	n.MaxRetries = 2
+
+	n.ObserveResult(pipeline.VoteResult{WalletAddress: "0xabc", Status: pipeline.StatusFailed})
+	n.Close()
+
+	if recv.attempts != 3 || len(recv.payloads) != 0 {
+		t.Fatalf("attempts = %d, delivered = %d; want 3 and 0", recv.attempts, len(recv.payloads))
+	}
+}
+
+func TestNotifierDropsWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	var delivered int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	}))
+	defer server.Close()
+	n := notify.New([]string{server.URL}, secret)
+
+	const sent = 200
+	queued := make(chan struct{})
+	go func() {
+		for i := 0; i < sent; i++ {
+			n.Notify(notify.Payload{Event: notify.EventVoteConfirmed, Wallet: "0xabc"})
+		}
+		close(queued)
+	}()
+	select {
+	case <-queued:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Notify blocked on a full queue")
+	}
+	close(release)
+	n.Close()
+
+	if delivered == 0 || delivered >= sent {
+		t.Fatalf("delivered = %d, want some but not all of %d", delivered, sent)
+	}
+}
+
+func TestNotifierLogsOnlyWebhookHost(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	n := notify.New([]string{dead.URL + "/hooks/s3cret?key=s3cret"}, secret)
+	n.MaxRetries = 0
+	n.Notify(notify.Payload{Event: notify.EventVoteConfirmed, Wallet: "0xabc"})
+	n.Close()
+
+	if !strings.Contains(logs.String(), "webhook delivery failed") || strings.Contains(logs.String(), "s3cret") {
+		t.Fatalf("logs = %q, want the failure logged without the URL secret", logs.String())
+	}
+}
+
+func TestNotifierIgnoresSkippedVotes(t *testing.T) {
+	recv := newReceiver(t, 0)
+	n := notify.New([]string{recv.URL}, secret)
+	n.ObserveResult(pipeline.VoteResult{WalletAddress: "0xabc", Status: pipeline.StatusSkipped, Error: "shutting down"})
+	n.Close()
+
+	if recv.attempts != 0 {
+		t.Fatalf("attempts = %d, want skipped votes not reported", recv.attempts)
+	}
+}
+
+func TestNotifierReportsLowBalanceOnce(t *testing.T) {
+	recv := newReceiver(t, 0)
+	n := notify.New([]string{recv.URL}, secret)
+	n.LowBalance = big.NewInt(1000)
+
+	health := func(balance int64) pipeline.Event {
+		return pipeline.Event{
+			Kind:   pipeline.EventCompleted,
+			Stage:  pipeline.StageHealth,
+			Health: &wallet.HealthReport{Address: "0xabc", Balance: big.NewInt(balance)},
+		}
+	}
+	n.ObserveEvent(health(500))
+	n.ObserveEvent(health(400))
+	n.ObserveEvent(health(5000))
+	n.ObserveEvent(health(10))
+	n.Close()
+
+	if len(recv.payloads) != 2 {
+		t.Fatalf("payloads = %+v, want one per drop below the threshold", recv.payloads)
+	}
+	if p := recv.payloads[0]; p.Event != notify.EventLowBalance || p.Wallet != "0xabc" || p.Threshold == "" {
+		t.Fatalf("payload = %+v", p)
+	}
+}
+
+func TestVerifyRejectsTamperedBody(t *testing.T) {
+	sig := notify.Sign(secret, []byte(`{"event":"vote.confirmed"}`))
+	if err := notify.Verify(secret, []byte(`{"event":"vote.failed"}`), sig); err == nil {
+		t.Fatal("Verify accepted a tampered body")
+	}
+	if err := notify.Verify("other", []byte(`{"event":"vote.confirmed"}`), sig); err == nil {
+		t.Fatal("Verify accepted the wrong secret")
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/logging"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+var stageFailureMessages = map[pipeline.Stage]string{
+	pipeline.StageHealth:          "❌ Startup health check failed",
+	pipeline.StageBudget:          "❌ Fee budget exhausted",
+	pipeline.StageSignIn:          "❌ Failed to authenticate",
+	pipeline.StageCreateOrder:     "❌ Failed to create vote order",
+	pipeline.StageValidateOrder:   "❌ Refusing to pay for vote order",
+	pipeline.StageSendTransaction: "❌ Failed to create vote transaction",
+	pipeline.StageWaitReceipt:     "❌ Failed to get transaction receipt",
+	pipeline.StageConfirmOrder:    "❌ Failed to confirm vote order",
+}
+
+func stageFailureMessage(stage pipeline.Stage) string {
+	if msg, ok := stageFailureMessages[stage]; ok {
+		return msg
+	}
+	return "❌ Vote failed"
+}
+
+type printer struct {
+	w    io.Writer
+	json bool
+
+	// prefixWallet tags progress lines with the wallet address when several wallets run concurrently.
+	prefixWallet bool
+	mu           sync.Mutex
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (expected text or json)", format)
+	}
+}
+
+func (p *printer) Printf(format string, args ...interface{}) {
+	if p.json {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, format, args...)
+}
+
+func (p *printer) result(v interface{}) {
+	if !p.json {
+		return
+	}
+	enc := json.NewEncoder(p.w)
+	if err := enc.Encode(v); err != nil {
+		slog.Error("failed to encode result", "error", err)
+	}
+}
+
+func (p *printer) fail(r *pipeline.VoteResult, msg string, err error) {
+	r.Status = pipeline.StatusFailed
+	r.Error = logging.Redact(err.Error())
+	p.result(r)
+	fatal(msg, err)
+}
+
+func (p *printer) progress(ev pipeline.Event) {
+	if p.prefixWallet {
+		p.progressLine(ev)
+		return
+	}
+
+	switch ev.Kind {
+	case pipeline.EventStarted:
+		switch ev.Stage {
+		case pipeline.StageCreateOrder:
+			p.Printf("🗳️ Creating vote order for candidate %s...\n", ev.Job.CandidateID)
+		case pipeline.StageSendTransaction:
+			p.Printf("⛓ Creating blockchain transaction...\n")
+		case pipeline.StageWaitReceipt:
+			p.Printf("⏳ Waiting for transaction confirmation (timeout: 5 minutes)...\n")
+		case pipeline.StageConfirmOrder:
+			p.Printf("✅ Confirming vote order...\n")
+		}
+	case pipeline.EventCompleted:
+		switch ev.Stage {
+		case pipeline.StageHealth:
+			p.printHealth(ev.Health)
+		case pipeline.StageSignIn:
+			p.Printf("🔑 Authentication successful\n")
+		case pipeline.StageCreateOrder:
+			p.printOrderDetails(ev.Order)
+		case pipeline.StageSendTransaction:
+			p.Printf("📝 Transaction hash: %s\n", ev.Result.TxHash)
+		case pipeline.StageWaitReceipt:
+			p.Printf("✅ Transaction confirmed in block %d\n", ev.Receipt.BlockNumber)
+		case pipeline.StageConfirmOrder:
+			p.Printf("\n🎉 Vote successfully submitted!\n")
+			p.Printf("🔗 Transaction: %s\n", ev.Result.TxHash)
+			p.Printf("🗳️ Candidate: %s\n", ev.Job.CandidateID)
+			p.Printf("🌎 Country: %s\n", ev.Job.TargetCountryID)
+		}
+	case pipeline.EventFailed:
+		if ev.Stage == pipeline.StageHealth {
+			p.printHealth(ev.Result.Health)
+		}
+	}
+}
+
+func (p *printer) progressLine(ev pipeline.Event) {
+	tag := ev.Result.WalletAddress
+	if len(tag) > 10 {
+		tag = tag[:6] + "…" + tag[len(tag)-4:]
+	}
+	switch ev.Kind {
+	case pipeline.EventCompleted:
+		switch ev.Stage {
+		case pipeline.StageSendTransaction:
+			p.Printf("[%s] 📝 Transaction hash: %s\n", tag, ev.Result.TxHash)
+		case pipeline.StageConfirmOrder:
+			p.Printf("[%s] 🎉 Vote confirmed (order %s)\n", tag, ev.Result.OrderID)
+		default:
+			p.Printf("[%s] ✔ %s\n", tag, ev.Stage)
+		}
+	case pipeline.EventFailed:
+		p.Printf("[%s] %s: %s\n", tag, stageFailureMessage(ev.Stage), logging.Redact(ev.Err.Error()))
+	}
+}
+
+func (p *printer) printSummary(s *pipeline.Summary) {
+	p.Printf("\n📊 Summary: %d confirmed, %d failed, %d skipped\n", s.Succeeded, s.Failed, s.Skipped)
+	for _, r := range s.Results {
+		switch r.Status {
+		case pipeline.StatusConfirmed:
+			p.Printf("• %s ✅ %s\n", r.WalletAddress, r.TxHash)
+		default:
+			p.Printf("• %s ❌ %s: %s\n", r.WalletAddress, r.Status, r.Error)
+		}
+	}
+}
+
+func (p *printer) printConfig(cfg *config.Config) {
+	p.Printf("\n⚙️ Configuration:\n")
+	if cfg.Profile != "" {
+		p.Printf("• Profile: %s\n", cfg.Profile)
+	}
+	p.Printf("• RPC URL: %s\n", cfg.RPCURL)
+	p.Printf("• Chain ID: %d\n", cfg.ChainID)
+	p.Printf("• API URL: %s\n", cfg.APIBaseURL)
+	p.Printf("• Target Country ID: %s\n", cfg.TargetCountryID)
+	p.Printf("• Candidate ID: %s\n", cfg.CandidateID)
+	p.Printf("• Feed Amount: %d\n", cfg.FeedAmount)
+	p.Printf("• Delay Seconds: %d\n", cfg.DelaySeconds)
+	if wallets := len(cfg.Keys()); wallets > 1 || cfg.VotesPerWallet > 1 {
+		p.Printf("• Wallets: %d (workers: %d, votes per wallet: %d)\n", wallets, cfg.Workers, cfg.VotesPerWallet)
+	}
+	if cfg.IntervalSeconds > 0 {
+		p.Printf("• Interval: every %ds\n", cfg.IntervalSeconds)
+	}
+	if cfg.HTTPAddr != "" {
+		p.Printf("• HTTP: %s\n", cfg.HTTPAddr)
+	}
+	if cfg.SignInFormat != "personal_sign" {
+		p.Printf("• Sign-in: %s\n", cfg.SignInFormat)
+	}
+	p.Printf("\n")
+}
+
+func (p *printer) printOrderDetails(order *api.OrderResponse) {
+	p.Printf("\n📄 Order Details:\n")
+	p.Printf("• Order ID: %s\n", order.Data.Order.ID)
+	p.Printf("• Status: %s\n", order.Data.Order.Status)
+	p.Printf("• Contract Address: %s\n", order.Data.Payment.ContractAddress)
+	p.Printf("• Function: %s\n", order.Data.Payment.FunctionName)
+	p.Printf("• Feed Amount: %d\n", order.Data.Payment.Params.FeedAmount)
+	p.Printf("\n")
+}
+
+func (p *printer) printHealth(h *wallet.HealthReport) {
+	p.Printf("\n🩺 Health Report:\n")
+	p.Printf("• RPC reachable: %t\n", h.RPCReachable)
+	if h.RPCReachable {
+		p.Printf("• Chain ID: %d (expected %d)\n", h.ChainID, h.ExpectedChainID)
+		p.Printf("• Latest block: %d\n", h.BlockNumber)
+	}
+	if h.Balance != nil {
+		p.Printf("• Balance: %s wei\n", h.Balance)
+	}
+	for _, problem := range h.Problems {
+		p.Printf("⚠️ %s\n", problem)
+	}
+	p.Printf("\n")
+}
@@ -0,0 +1,141 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+const StatusSkipped = "skipped"
+
+// WalletJobs is the ordered list of votes for one wallet. Jobs for the same
+// wallet always run sequentially so nonces are consumed in order.
+type WalletJobs struct {
+	Wallet *wallet.Wallet
+	Jobs   []VoteJob
+}
+
+type Summary struct {
+	Results   []VoteResult `json:"results"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Skipped   int          `json:"skipped"`
+}
+
+type Pool struct {
+	Workers int
+
+	// NewRunner builds the runner used for a single wallet.
+	NewRunner func(w *wallet.Wallet) *Runner
+
+	// OnResult, if set, is called from the worker goroutine as soon as each job finishes or is skipped.
+	OnResult func(VoteResult)
+
+	// Stopping, once closed, keeps the pool from starting further votes.
+	// Votes already running continue until ctx is done.
+	Stopping <-chan struct{}
+}
+
+func (s *Summary) Add(result VoteResult) {
+	switch result.Status {
+	case StatusConfirmed:
+		s.Succeeded++
+	case StatusSkipped:
+		s.Skipped++
+	default:
+		s.Failed++
+	}
+	s.Results = append(s.Results, result)
+}
+
+func (p *Pool) Run(ctx context.Context, work []WalletJobs) *Summary {
+	workers := p.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(work) {
+		workers = len(work)
+	}
+
+	results := make([][]VoteResult, len(work))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				results[idx] = p.runWallet(ctx, work[idx])
+			}
+		}()
+	}
+
+	for idx := range work {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+
+	summary := &Summary{}
+	for _, walletResults := range results {
+		for _, result := range walletResults {
+			summary.Add(result)
+		}
+	}
+	return summary
+}
+
+func (p *Pool) runWallet(ctx context.Context, work WalletJobs) []VoteResult {
+	runner := p.NewRunner(work.Wallet)
+	results := make([]VoteResult, 0, len(work.Jobs))
+
+	add := func(result VoteResult) {
+		results = append(results, result)
+		if p.OnResult != nil {
+			p.OnResult(result)
+		}
+	}
+
+	for i, job := range work.Jobs {
+		if err := ctx.Err(); err != nil {
+			add(skipped(work.Wallet, job, err.Error()))
+			continue
+		}
+		if p.stopping() {
+			add(skipped(work.Wallet, job, "shutting down"))
+			continue
+		}
+
+		result, err := runner.Run(ctx, job)
+		add(result)
+		if err != nil {
+			for _, rest := range work.Jobs[i+1:] {
+				add(skipped(work.Wallet, rest, "previous vote for this wallet failed"))
+			}
+			break
+		}
+	}
+	return results
+}
+
+func (p *Pool) stopping() bool {
+	select {
+	case <-p.Stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+func skipped(w *wallet.Wallet, job VoteJob, reason string) VoteResult {
+	return VoteResult{
+		WalletAddress:   w.GetAddress(),
+		CandidateID:     job.CandidateID,
+		TargetCountryID: job.TargetCountryID,
+		FeedAmount:      job.FeedAmount,
+		Status:          StatusSkipped,
+		Error:           reason,
+	}
+}
@@ -0,0 +1,101 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+func TestPoolRunsWalletsConcurrentlyInOrder(t *testing.T) {
+	env := newTestEnv(t,
+		"0x8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63",
+		"0xc87509a1c067bbde78beb793e6fa76530b6382a4c0241e5e4a9ec0a0f44dc0d3",
+	)
+
+	limits := wallet.NewRateLimits(200, 20)
+	var work []pipeline.WalletJobs
+	for _, w := range env.Wallets {
+		w.SetDialer(limits.Dialer(env.Chain.Dialer()))
+		work = append(work, pipeline.WalletJobs{
+			Wallet: w,
+			Jobs:   []pipeline.VoteJob{pipeline.JobFromConfig(env.cfg), pipeline.JobFromConfig(env.cfg)},
+		})
+	}
+
+	pool := &pipeline.Pool{
+		Workers: 2,
+		NewRunner: func(w *wallet.Wallet) *pipeline.Runner {
+			return pipeline.NewRunner(w, env.Server.Client())
+		},
+	}
+	summary := pool.Run(context.Background(), work)
+
+	if summary.Succeeded != 6 || summary.Failed != 0 {
+		t.Fatalf("summary = %+v, want 6 confirmed", summary)
+	}
+
+	client := env.Chain.Backend.Client()
+	for i, w := range env.Wallets {
+		first, second := summary.Results[2*i], summary.Results[2*i+1]
+		if first.WalletAddress != w.GetAddress() || second.WalletAddress != w.GetAddress() {
+			t.Fatalf("results for wallet %d are out of order: %+v", i, summary.Results)
+		}
+
+		tx1, _, err := client.TransactionByHash(context.Background(), common.HexToHash(first.TxHash))
+		if err != nil {
+			t.Fatalf("TransactionByHash: %v", err)
+		}
+		tx2, _, err := client.TransactionByHash(context.Background(), common.HexToHash(second.TxHash))
+		if err != nil {
+			t.Fatalf("TransactionByHash: %v", err)
+		}
+		if tx2.Nonce() != tx1.Nonce()+1 {
+			t.Fatalf("wallet %d nonces = %d, %d; want consecutive", i, tx1.Nonce(), tx2.Nonce())
+		}
+	}
+}
+
+func TestPoolSkipsRemainingJobsAfterFailure(t *testing.T) {
+	env := newTestEnv(t)
+	env.cfg.ChainID = 10143
+
+	pool := &pipeline.Pool{
+		Workers: 1,
+		NewRunner: func(w *wallet.Wallet) *pipeline.Runner {
+			return pipeline.NewRunner(w, env.Server.Client())
+		},
+	}
+	summary := pool.Run(context.Background(), []pipeline.WalletJobs{{
+		Wallet: env.Wallet,
+		Jobs:   []pipeline.VoteJob{pipeline.JobFromConfig(env.cfg), pipeline.JobFromConfig(env.cfg)},
+	}})
+
+	if summary.Failed != 1 || summary.Skipped != 1 {
+		t.Fatalf("summary = %+v, want 1 failed and 1 skipped", summary)
+	}
+}
+
+func TestPoolStartsNothingOnceStopping(t *testing.T) {
+	env := newTestEnv(t)
+
+	stopping := make(chan struct{})
+	close(stopping)
+	pool := &pipeline.Pool{
+		NewRunner: func(w *wallet.Wallet) *pipeline.Runner {
+			return pipeline.NewRunner(w, env.Server.Client())
+		},
+		Stopping: stopping,
+	}
+	summary := pool.Run(context.Background(), []pipeline.WalletJobs{{
+		Wallet: env.Wallet,
+		Jobs:   []pipeline.VoteJob{pipeline.JobFromConfig(env.cfg), pipeline.JobFromConfig(env.cfg)},
+	}})
+
+	if summary.Skipped != 2 || env.Server.Requests(apitest.RouteSignIn) != 0 {
+		t.Fatalf("summary = %+v, want both votes skipped without contacting the API", summary)
+	}
+}
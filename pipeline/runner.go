@@ -0,0 +1,456 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/logging"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+type Stage string
+
+const (
+	StageHealth          Stage = "health"
+	StageBudget          Stage = "budget"
+	StageSignIn          Stage = "sign-in"
+	StageCreateOrder     Stage = "create-order"
+	StageValidateOrder   Stage = "validate-order"
+	StageSendTransaction Stage = "send-transaction"
+	StageWaitReceipt     Stage = "wait-receipt"
+	StageConfirmOrder    Stage = "confirm-order"
+)
+
+const (
+	StatusConfirmed = "confirmed"
+	StatusFailed    = "failed"
+)
+
+type VoteJob struct {
+	RPCURL          string
+	ChainID         int64
+	WalletID        string
+	CandidateID     string
+	TargetCountryID string
+	FeedAmount      int
+
+	// AllowedContracts lists the contracts an order may ask us to pay.
+	AllowedContracts []string
+	// IntegritySigner, when set, must have signed every order's integrity
+	// signature.
+	IntegritySigner string
+}
+
+func JobFromConfig(cfg *config.Config) VoteJob {
+	return VoteJob{
+		RPCURL:           cfg.RPCURL,
+		ChainID:          cfg.ChainID,
+		WalletID:         cfg.WalletID,
+		CandidateID:      cfg.CandidateID,
+		TargetCountryID:  cfg.TargetCountryID,
+		FeedAmount:       cfg.FeedAmount,
+		AllowedContracts: cfg.AllowedContractsFor(cfg.ChainID),
+		IntegritySigner:  cfg.IntegritySigner,
+	}
+}
+
+type VoteResult struct {
+	WalletAddress     string               `json:"walletAddress,omitempty"`
+	CandidateID       string               `json:"candidateId,omitempty"`
+	TargetCountryID   string               `json:"targetCountryId,omitempty"`
+	FeedAmount        int                  `json:"feedAmount,omitempty"`
+	OrderID           string               `json:"orderId,omitempty"`
+	TxHash            string               `json:"txHash,omitempty"`
+	BlockNumber       uint64               `json:"blockNumber,omitempty"`
+	GasUsed           uint64               `json:"gasUsed,omitempty"`
+	EffectiveGasPrice string               `json:"effectiveGasPrice,omitempty"`
+	EffectiveFee      string               `json:"effectiveFee,omitempty"`
+	Health            *wallet.HealthReport `json:"health,omitempty"`
+	Stage             Stage                `json:"stage,omitempty"`
+	Status            string               `json:"status"`
+	Error             string               `json:"error,omitempty"`
+}
+
+func (r *VoteResult) setReceipt(receipt *types.Receipt) {
+	if receipt.BlockNumber != nil {
+		r.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	r.GasUsed = receipt.GasUsed
+	if receipt.EffectiveGasPrice != nil {
+		r.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+		r.EffectiveFee = fee.String()
+	}
+}
+
+func (r *VoteResult) feePaid() *big.Int {
+	if fee, ok := new(big.Int).SetString(r.EffectiveFee, 10); ok {
+		return fee
+	}
+	if r.TxHash != "" {
+		return nil
+	}
+	return new(big.Int)
+}
+
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+type EventKind string
+
+const (
+	EventStarted   EventKind = "started"
+	EventCompleted EventKind = "completed"
+	EventFailed    EventKind = "failed"
+)
+
+// Event is delivered to hooks as the runner enters, finishes or fails a stage.
+// Only the fields relevant to the stage are set; Elapsed is the time spent in
+// the stage and is zero for started events.
+type Event struct {
+	Kind    EventKind
+	Stage   Stage
+	Job     VoteJob
+	Result  *VoteResult
+	Health  *wallet.HealthReport
+	Order   *api.OrderResponse
+	Receipt *types.Receipt
+	Elapsed time.Duration
+	Err     error
+}
+
+type Hook func(Event)
+
+// Budget gates new votes on fee spending. Reserve returns a settle function
+// that the runner calls exactly once with the fee actually paid: nil when a
+// transaction was sent but its fee is unknown, zero when nothing was sent.
+type Budget interface {
+	Reserve(walletAddress string) (settle func(fee *big.Int), err error)
+}
+
+// PendingOrders finds an order that an earlier run created for the same
+// wallet and candidate but never paid for, so it can be reused instead of
+// creating a second order for the same vote.
+type PendingOrders interface {
+	PendingOrder(walletAddress, candidateID string) (orderID string, ok bool)
+}
+
+type Runner struct {
+	Wallet      *wallet.Wallet
+	API         *api.Client
+	Budget      Budget
+	Orders      PendingOrders
+	MaxAttempts int
+	RetryDelay  time.Duration
+
+	hooks []Hook
+}
+
+func NewRunner(w *wallet.Wallet, client *api.Client) *Runner {
+	return &Runner{
+		Wallet:      w,
+		API:         client,
+		MaxAttempts: 1,
+	}
+}
+
+func (r *Runner) OnEvent(hook Hook) {
+	r.hooks = append(r.hooks, hook)
+}
+
+func (r *Runner) emit(ev Event) {
+	for _, hook := range r.hooks {
+		hook(ev)
+	}
+}
+
+func (r *Runner) Run(ctx context.Context, job VoteJob) (VoteResult, error) {
+	v := r.newVote(job)
+	err := v.run(ctx)
+	return v.finish(err)
+}
+
+// Resume completes a vote whose transaction was already sent but whose order
+// was never confirmed: it signs in again, waits for the receipt and confirms.
+func (r *Runner) Resume(ctx context.Context, job VoteJob, orderID, txHash string) (VoteResult, error) {
+	v := r.newVote(job)
+	v.result.OrderID = orderID
+	v.result.TxHash = txHash
+
+	err := func() error {
+		token, err := v.signIn(ctx)
+		if err != nil {
+			return err
+		}
+		return v.confirm(ctx, token)
+	}()
+	return v.finish(err)
+}
+
+// vote tracks one pass through the pipeline and emits its stage events.
+type vote struct {
+	r          *Runner
+	job        VoteJob
+	result     *VoteResult
+	stageStart time.Time
+}
+
+func (r *Runner) newVote(job VoteJob) *vote {
+	return &vote{
+		r:   r,
+		job: job,
+		result: &VoteResult{
+			WalletAddress:   r.Wallet.GetAddress(),
+			CandidateID:     job.CandidateID,
+			TargetCountryID: job.TargetCountryID,
+			FeedAmount:      job.FeedAmount,
+			Status:          StatusFailed,
+		},
+	}
+}
+
+func (v *vote) finish(err error) (VoteResult, error) {
+	if err != nil {
+		v.result.Error = logging.Redact(err.Error())
+	}
+	return *v.result, err
+}
+
+func (v *vote) ev(kind EventKind, stage Stage) Event {
+	v.result.Stage = stage
+	e := Event{Kind: kind, Stage: stage, Job: v.job, Result: v.result}
+	if kind == EventStarted {
+		v.stageStart = time.Now()
+	} else {
+		e.Elapsed = time.Since(v.stageStart)
+	}
+	return e
+}
+
+func (v *vote) start(stage Stage) {
+	v.r.emit(v.ev(EventStarted, stage))
+}
+
+func (v *vote) complete(stage Stage) {
+	v.r.emit(v.ev(EventCompleted, stage))
+}
+
+func (v *vote) fail(stage Stage, err error) error {
+	e := v.ev(EventFailed, stage)
+	e.Err = err
+	v.r.emit(e)
+	return &StageError{Stage: stage, Err: err}
+}
+
+func (v *vote) run(ctx context.Context) error {
+	r, job, result := v.r, v.job, v.result
+
+	v.start(StageHealth)
+	health := r.Wallet.CheckHealth(ctx, job.RPCURL, job.ChainID)
+	result.Health = health
+	if err := health.Err(); err != nil {
+		return v.fail(StageHealth, err)
+	}
+	done := v.ev(EventCompleted, StageHealth)
+	done.Health = health
+	r.emit(done)
+
+	if r.Budget != nil {
+		v.start(StageBudget)
+		settle, err := r.Budget.Reserve(result.WalletAddress)
+		if err != nil {
+			return v.fail(StageBudget, err)
+		}
+		defer func() { settle(result.feePaid()) }()
+		v.complete(StageBudget)
+	}
+
+	token, err := v.signIn(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return v.fail(StageCreateOrder, err)
+	}
+	v.start(StageCreateOrder)
+	order := v.pendingOrder(token)
+	if order == nil {
+		order, err = r.API.CreateVoteOrder(
+			token,
+			job.CandidateID,
+			fmt.Sprint(job.ChainID),
+			job.TargetCountryID,
+			job.RPCURL,
+			job.WalletID,
+			job.FeedAmount,
+		)
+		if err != nil {
+			return v.fail(StageCreateOrder, err)
+		}
+	}
+	result.OrderID = order.Data.Order.ID
+	done = v.ev(EventCompleted, StageCreateOrder)
+	done.Order = order
+	r.emit(done)
+
+	v.start(StageValidateOrder)
+	if err := ValidateOrder(order, job); err != nil {
+		return v.fail(StageValidateOrder, err)
+	}
+	if job.IntegritySigner != "" {
+		if err := VerifyIntegrity(order, job, result.WalletAddress); err != nil {
+			return v.fail(StageValidateOrder, err)
+		}
+	}
+	v.complete(StageValidateOrder)
+
+	if err := ctx.Err(); err != nil {
+		return v.fail(StageSendTransaction, err)
+	}
+	v.start(StageSendTransaction)
+	txHash, err := r.Wallet.CreateVoteTransaction(
+		job.RPCURL,
+		order.Data.Payment.ContractAddress,
+		job.CandidateID,
+		job.FeedAmount,
+		job.ChainID,
+		order.Data.Order.ID,
+		order.Data.Payment.Params.RequestData,
+		order.Data.Payment.Params.UserHashedMessage,
+		order.Data.Payment.Params.IntegritySignature,
+	)
+	if err != nil {
+		return v.fail(StageSendTransaction, err)
+	}
+	result.TxHash = txHash
+	v.complete(StageSendTransaction)
+
+	return v.confirm(ctx, token)
+}
+
+func (v *vote) signIn(ctx context.Context) (string, error) {
+	v.start(StageSignIn)
+	var token string
+	err := v.r.retry(ctx, StageSignIn, func() error {
+		var err error
+		token, err = v.r.API.WalletSignIn(v.r.Wallet)
+		return err
+	})
+	if err != nil {
+		return "", v.fail(StageSignIn, err)
+	}
+	v.complete(StageSignIn)
+	return token, nil
+}
+
+// confirm waits for the vote transaction and confirms the order with the API.
+func (v *vote) confirm(ctx context.Context, token string) error {
+	r, job, result := v.r, v.job, v.result
+
+	v.start(StageWaitReceipt)
+	receipt, err := r.Wallet.WaitForTransactionReceiptContext(ctx, job.RPCURL, result.TxHash)
+	if err != nil {
+		return v.fail(StageWaitReceipt, err)
+	}
+	result.setReceipt(receipt)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return v.fail(StageWaitReceipt, fmt.Errorf("transaction %s reverted", result.TxHash))
+	}
+	slog.Info("transaction confirmed", "tx_hash", result.TxHash, "block", receipt.BlockNumber, "gas_used", receipt.GasUsed)
+	done := v.ev(EventCompleted, StageWaitReceipt)
+	done.Receipt = receipt
+	r.emit(done)
+
+	if err := ctx.Err(); err != nil {
+		return v.fail(StageConfirmOrder, err)
+	}
+	v.start(StageConfirmOrder)
+	err = r.retry(ctx, StageConfirmOrder, func() error {
+		if v.alreadyConfirmed(token) {
+			return nil
+		}
+		return r.API.ConfirmVoteOrder(token, result.OrderID, result.TxHash)
+	})
+	if err != nil {
+		return v.fail(StageConfirmOrder, err)
+	}
+	result.Status = StatusConfirmed
+	v.complete(StageConfirmOrder)
+
+	return nil
+}
+
+// pendingOrder returns the earlier unpaid order for this vote if the API
+// still has it pending with the same parameters.
+func (v *vote) pendingOrder(token string) *api.OrderResponse {
+	if v.r.Orders == nil {
+		return nil
+	}
+	orderID, ok := v.r.Orders.PendingOrder(v.result.WalletAddress, v.job.CandidateID)
+	if !ok {
+		return nil
+	}
+
+	order, err := v.r.API.GetVoteOrder(token, orderID)
+	if err != nil {
+		slog.Warn("could not look up earlier order, creating a new one", "order_id", orderID, "error", err)
+		return nil
+	}
+	params := order.Data.Payment.Params
+	if order.Data.Order.Status != api.OrderStatusPending || params.CandidateID != v.job.CandidateID || params.FeedAmount != v.job.FeedAmount {
+		slog.Info("earlier order is no longer usable, creating a new one", "order_id", orderID, "status", order.Data.Order.Status)
+		return nil
+	}
+	if order.Data.Order.ID == "" {
+		order.Data.Order.ID = orderID
+	}
+	slog.Info("reusing pending order from an earlier run", "order_id", orderID)
+	return order
+}
+
+// alreadyConfirmed guards against confirming twice when an earlier confirm
+// reached the API but its response was lost.
+func (v *vote) alreadyConfirmed(token string) bool {
+	order, err := v.r.API.GetVoteOrder(token, v.result.OrderID)
+	if err != nil {
+		slog.Debug("could not check order status before confirming", "order_id", v.result.OrderID, "error", err)
+		return false
+	}
+	if order.Data.Order.Status != api.OrderStatusConfirmed {
+		return false
+	}
+	slog.Info("order already confirmed, skipping confirm", "order_id", v.result.OrderID)
+	return true
+}
+
+func (r *Runner) retry(ctx context.Context, stage Stage, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.MaxAttempts {
+			return err
+		}
+		slog.Warn("attempt failed, retrying", "stage", stage, "attempt", attempt, "max_attempts", r.MaxAttempts, "error", err)
+		select {
+		case <-time.After(r.RetryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
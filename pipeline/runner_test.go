@@ -0,0 +1,449 @@
+package pipeline_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/chaintest"
+	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+type testEnv struct {
+	*chaintest.Env
+	cfg *config.Config
+}
+
+func (e *testEnv) run() (pipeline.VoteResult, error) {
+	runner := pipeline.NewRunner(e.Wallet, e.Server.Client())
+	runner.MaxAttempts = e.cfg.MaxAttempts
+	return runner.Run(context.Background(), pipeline.JobFromConfig(e.cfg))
+}
+
+func newTestEnv(t *testing.T, extraKeys ...string) *testEnv {
+	t.Helper()
+	env := chaintest.NewEnv(t, extraKeys...)
+
+	cfg := config.Default()
+	cfg.PrivateKey = chaintest.TestPrivateKey
+	cfg.RPCURL = "simulated://chain"
+	cfg.ChainID = chaintest.ChainID
+	cfg.APIBaseURL = env.Server.URL
+	cfg.WalletID = "test-wallet"
+	cfg.TargetCountryID = "VN"
+	cfg.CandidateID = "678dbb6579af53b8da5ddf3d"
+	cfg.FeedAmount = 3
+	cfg.MaxAttempts = 1
+	cfg.DelaySeconds = 0
+
+	return &testEnv{Env: env, cfg: cfg}
+}
+
+func TestRunnerEndToEnd(t *testing.T) {
+	env := newTestEnv(t)
+
+	result, err := env.run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if result.Status != pipeline.StatusConfirmed {
+		t.Fatalf("status = %q, want %q", result.Status, pipeline.StatusConfirmed)
+	}
+	if result.BlockNumber == 0 || result.GasUsed == 0 || result.EffectiveFee == "" {
+		t.Fatalf("receipt details missing from result: %+v", result)
+	}
+
+	order, ok := env.Server.Order(result.OrderID)
+	if !ok {
+		t.Fatalf("order %s not found on mock server", result.OrderID)
+	}
+	if order.Status != apitest.OrderStatusConfirmed || order.TxHash != result.TxHash {
+		t.Fatalf("order = %+v, want confirmed with tx %s", order, result.TxHash)
+	}
+
+	receipt, err := env.Chain.Backend.Client().TransactionReceipt(context.Background(), common.HexToHash(result.TxHash))
+	if err != nil {
+		t.Fatalf("TransactionReceipt: %v", err)
+	}
+	if len(receipt.Logs) != 1 || receipt.Logs[0].Topics[0] != chaintest.FedEventTopic {
+		t.Fatalf("expected one Fed event from the stub, got %+v", receipt.Logs)
+	}
+	if got := common.BytesToHash(receipt.Logs[0].Topics[1].Bytes()); common.BytesToAddress(got.Bytes()) != common.HexToAddress(result.WalletAddress) {
+		t.Fatalf("Fed voter = %s, want %s", got.Hex(), result.WalletAddress)
+	}
+	if got := new(big.Int).SetBytes(receipt.Logs[0].Data).Int64(); got != int64(env.cfg.FeedAmount) {
+		t.Fatalf("Fed feedAmount = %d, want %d", got, env.cfg.FeedAmount)
+	}
+}
+
+func TestRunnerConfirmFailureKeepsTxHash(t *testing.T) {
+	env := newTestEnv(t)
+	env.Server.Fail(apitest.RouteConfirmOrder, 1, http.StatusInternalServerError, map[string]string{"message": "boom"})
+
+	result, err := env.run()
+	var se *pipeline.StageError
+	if !errors.As(err, &se) || se.Stage != pipeline.StageConfirmOrder {
+		t.Fatalf("err = %v, want confirm-order stage error", err)
+	}
+	if result.Status != pipeline.StatusFailed || result.TxHash == "" || result.OrderID == "" {
+		t.Fatalf("result = %+v, want failed with order and tx hash", result)
+	}
+
+	order, _ := env.Server.Order(result.OrderID)
+	if order.Status != apitest.OrderStatusPending {
+		t.Fatalf("order status = %q, want %q", order.Status, apitest.OrderStatusPending)
+	}
+}
+
+func TestRunnerRefusesChainIDMismatch(t *testing.T) {
+	env := newTestEnv(t)
+	env.cfg.ChainID = 10143
+
+	result, err := env.run()
+	if !errors.Is(err, wallet.ErrChainIDMismatch) {
+		t.Fatalf("err = %v, want ErrChainIDMismatch", err)
+	}
+	if result.TxHash != "" || env.Server.Requests(apitest.RouteCreateOrder) != 0 {
+		t.Fatalf("Run went past the health check: %+v", result)
+	}
+}
+
+func TestRunnerEmitsStageEvents(t *testing.T) {
+	env := newTestEnv(t)
+
+	runner := pipeline.NewRunner(env.Wallet, env.Server.Client())
+	var completed []pipeline.Stage
+	runner.OnEvent(func(ev pipeline.Event) {
+		if ev.Kind == pipeline.EventCompleted {
+			completed = append(completed, ev.Stage)
+		}
+	})
+
+	if _, err := runner.Run(context.Background(), pipeline.JobFromConfig(env.cfg)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := []pipeline.Stage{
+		pipeline.StageHealth,
+		pipeline.StageSignIn,
+		pipeline.StageCreateOrder,
+		pipeline.StageValidateOrder,
+		pipeline.StageSendTransaction,
+		pipeline.StageWaitReceipt,
+		pipeline.StageConfirmOrder,
+	}
+	if len(completed) != len(want) {
+		t.Fatalf("completed stages = %v, want %v", completed, want)
+	}
+	for i := range want {
+		if completed[i] != want[i] {
+			t.Fatalf("completed stages = %v, want %v", completed, want)
+		}
+	}
+}
+
+type denyBudget struct{}
+
+func (denyBudget) Reserve(string) (func(*big.Int), error) {
+	return nil, errors.New("budget exhausted")
+}
+
+type recordingBudget struct {
+	fees []*big.Int
+}
+
+func (b *recordingBudget) Reserve(string) (func(*big.Int), error) {
+	return func(fee *big.Int) { b.fees = append(b.fees, fee) }, nil
+}
+
+func TestRunnerRespectsBudget(t *testing.T) {
+	env := newTestEnv(t)
+
+	runner := pipeline.NewRunner(env.Wallet, env.Server.Client())
+	runner.Budget = denyBudget{}
+	_, err := runner.Run(context.Background(), pipeline.JobFromConfig(env.cfg))
+	var se *pipeline.StageError
+	if !errors.As(err, &se) || se.Stage != pipeline.StageBudget {
+		t.Fatalf("err = %v, want budget stage error", err)
+	}
+	if env.Server.Requests(apitest.RouteCreateOrder) != 0 {
+		t.Fatal("order created despite exhausted budget")
+	}
+
+	budget := &recordingBudget{}
+	runner.Budget = budget
+	result, err := runner.Run(context.Background(), pipeline.JobFromConfig(env.cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(budget.fees) != 1 || budget.fees[0] == nil || budget.fees[0].String() != result.EffectiveFee {
+		t.Fatalf("settled fees = %v, want [%s]", budget.fees, result.EffectiveFee)
+	}
+}
+
+func TestRunnerResumeConfirmsInterruptedVote(t *testing.T) {
+	env := newTestEnv(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := pipeline.NewRunner(env.Wallet, env.Server.Client())
+	runner.OnEvent(func(ev pipeline.Event) {
+		if ev.Kind == pipeline.EventStarted && ev.Stage == pipeline.StageWaitReceipt {
+			cancel()
+		}
+	})
+	job := pipeline.JobFromConfig(env.cfg)
+
+	interrupted, err := runner.Run(ctx, job)
+	if !errors.Is(err, context.Canceled) || interrupted.Stage != pipeline.StageWaitReceipt {
+		t.Fatalf("Run = %+v, %v; want canceled while waiting for the receipt", interrupted, err)
+	}
+	if interrupted.OrderID == "" || interrupted.TxHash == "" {
+		t.Fatalf("interrupted result lost the order or tx hash: %+v", interrupted)
+	}
+
+	resumed, err := pipeline.NewRunner(env.Wallet, env.Server.Client()).Resume(context.Background(), job, interrupted.OrderID, interrupted.TxHash)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if resumed.Status != pipeline.StatusConfirmed || resumed.GasUsed == 0 {
+		t.Fatalf("resumed = %+v, want confirmed with receipt details", resumed)
+	}
+	if order, _ := env.Server.Order(interrupted.OrderID); order.Status != apitest.OrderStatusConfirmed || order.TxHash != interrupted.TxHash {
+		t.Fatalf("order = %+v, want confirmed with tx %s", order, interrupted.TxHash)
+	}
+	if env.Server.Requests(apitest.RouteCreateOrder) != 1 {
+		t.Fatal("Resume created a new order")
+	}
+}
+
+func TestRunnerSkipsConfirmWhenAlreadyConfirmed(t *testing.T) {
+	env := newTestEnv(t)
+	lost := false
+	env.Server.Respond(apitest.RouteConfirmOrder, func(r *http.Request) *apitest.Response {
+		if lost {
+			return nil
+		}
+		lost = true
+		parts := strings.Split(strings.TrimSuffix(r.URL.Path, "/confirm"), "/")
+		env.Server.ConfirmOrder(parts[len(parts)-1], "0xlost")
+		return &apitest.Response{Status: http.StatusGatewayTimeout, Body: map[string]string{"message": "timeout"}}
+	})
+
+	runner := pipeline.NewRunner(env.Wallet, env.Server.Client())
+	runner.MaxAttempts = 2
+	result, err := runner.Run(context.Background(), pipeline.JobFromConfig(env.cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Status != pipeline.StatusConfirmed {
+		t.Fatalf("status = %q, want confirmed", result.Status)
+	}
+	if got := env.Server.Requests(apitest.RouteConfirmOrder); got != 1 {
+		t.Fatalf("confirm requests = %d, want 1 (the retry should see the order already confirmed)", got)
+	}
+}
+
+type pendingOrders map[string]string
+
+func (p pendingOrders) PendingOrder(walletAddress, candidateID string) (string, bool) {
+	id, ok := p[walletAddress+"/"+candidateID]
+	return id, ok
+}
+
+func TestRunnerReusesPendingOrder(t *testing.T) {
+	env := newTestEnv(t)
+	client := env.Server.Client()
+
+	token, err := client.WalletSignIn(env.Wallet)
+	if err != nil {
+		t.Fatalf("WalletSignIn: %v", err)
+	}
+	earlier, err := client.CreateVoteOrder(token, env.cfg.CandidateID, "1337", env.cfg.TargetCountryID, env.cfg.RPCURL, env.cfg.WalletID, env.cfg.FeedAmount)
+	if err != nil {
+		t.Fatalf("CreateVoteOrder: %v", err)
+	}
+
+	runner := pipeline.NewRunner(env.Wallet, client)
+	runner.Orders = pendingOrders{env.Wallet.GetAddress() + "/" + env.cfg.CandidateID: earlier.Data.Order.ID}
+	result, err := runner.Run(context.Background(), pipeline.JobFromConfig(env.cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.OrderID != earlier.Data.Order.ID || env.Server.Requests(apitest.RouteCreateOrder) != 1 {
+		t.Fatalf("order = %s after %d create requests; want %s reused", result.OrderID, env.Server.Requests(apitest.RouteCreateOrder), earlier.Data.Order.ID)
+	}
+
+	// Once confirmed, the same order must not be reused.
+	result, err = runner.Run(context.Background(), pipeline.JobFromConfig(env.cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.OrderID == earlier.Data.Order.ID || env.Server.Requests(apitest.RouteCreateOrder) != 2 {
+		t.Fatalf("confirmed order %s was reused", earlier.Data.Order.ID)
+	}
+}
+
+func TestRunnerRefusesTamperedOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(env *testEnv)
+		want   string
+	}{
+		{"contract not allowed", func(env *testEnv) {
+			env.cfg.AllowedContracts = []string{"0x000000000000000000000000000000000000dEaD"}
+		}, "allowlist"},
+		{"function", func(env *testEnv) { env.Server.Payment.FunctionName = "withdraw" }, `"withdraw"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tt.tamper(env)
+
+			_, err := env.run()
+			var se *pipeline.StageError
+			if !errors.As(err, &se) || se.Stage != pipeline.StageValidateOrder {
+				t.Fatalf("err = %v, want validate-order stage error", err)
+			}
+			if !errors.Is(err, pipeline.ErrInvalidOrder) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want ErrInvalidOrder mentioning %s", err, tt.want)
+			}
+			if nonce, _ := env.Chain.Backend.Client().PendingNonceAt(context.Background(), common.HexToAddress(env.Wallet.GetAddress())); nonce != 0 {
+				t.Fatalf("nonce = %d, want no transaction sent", nonce)
+			}
+		})
+	}
+}
+
+func TestRunnerAcceptsAllowedContract(t *testing.T) {
+	env := newTestEnv(t)
+	env.cfg.AllowedContracts = []string{fmt.Sprintf("%d:%s", chaintest.ChainID, strings.ToLower(chaintest.FeedStubAddress.Hex()))}
+
+	if _, err := env.run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestRunnerEnforcesContractPolicy(t *testing.T) {
+	stubHash := crypto.Keccak256Hash(chaintest.FeedStubCode())
+	empty := common.HexToAddress("0x00000000000000000000000000000000000000E1")
+	tests := []struct {
+		name     string
+		contract common.Address
+		codeHash common.Hash
+		refuse   bool
+		wantErr  error
+	}{
+		{"pinned", chaintest.FeedStubAddress, stubHash, false, nil},
+		{"unpinned", chaintest.FeedStubAddress, common.Hash{}, false, nil},
+		{"hash mismatch", chaintest.FeedStubAddress, common.HexToHash("0x01"), true, wallet.ErrCodeHashMismatch},
+		{"no code", empty, common.Hash{}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.Server.Payment.ContractAddress = tt.contract.Hex()
+			policy := wallet.NewContractPolicy()
+			policy.Allow(chaintest.ChainID, tt.contract, tt.codeHash)
+			env.Wallet.SetContractPolicy(policy)
+
+			_, err := env.run()
+			if !tt.refuse {
+				if err != nil {
+					t.Fatalf("Run: %v", err)
+				}
+				return
+			}
+			var se *pipeline.StageError
+			if !errors.As(err, &se) || se.Stage != pipeline.StageSendTransaction || !strings.Contains(err.Error(), "refusing to sign") {
+				t.Fatalf("err = %v, want send-transaction refusal", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if nonce, _ := env.Chain.Backend.Client().PendingNonceAt(context.Background(), common.HexToAddress(env.Wallet.GetAddress())); nonce != 0 {
+				t.Fatalf("nonce = %d, want no transaction sent", nonce)
+			}
+		})
+	}
+}
+
+func TestRunnerRefusesContractMissingFromPolicy(t *testing.T) {
+	env := newTestEnv(t)
+	policy := wallet.NewContractPolicy()
+	policy.Allow(chaintest.ChainID, common.HexToAddress("0x000000000000000000000000000000000000dEaD"), common.Hash{})
+	env.Wallet.SetContractPolicy(policy)
+
+	if _, err := env.run(); !errors.Is(err, wallet.ErrContractNotAllowed) {
+		t.Fatalf("err = %v, want %v", err, wallet.ErrContractNotAllowed)
+	}
+}
+
+func TestRunnerRefusesChainMissingFromPolicy(t *testing.T) {
+	env := newTestEnv(t)
+	policy := wallet.NewContractPolicy()
+	policy.Allow(1, chaintest.FeedStubAddress, common.Hash{})
+	env.Wallet.SetContractPolicy(policy)
+
+	if _, err := env.run(); !errors.Is(err, wallet.ErrContractNotAllowed) {
+		t.Fatalf("err = %v, want %v", err, wallet.ErrContractNotAllowed)
+	}
+}
+
+func TestRunnerChecksRequestHashWithoutSigner(t *testing.T) {
+	env := newTestEnv(t)
+	env.Server.Payment.UserHashedMessage = "0x" + strings.Repeat("11", 32)
+
+	var se *pipeline.StageError
+	if _, err := env.run(); !errors.As(err, &se) || se.Stage != pipeline.StageValidateOrder || !strings.Contains(err.Error(), "does not match the hash of requestData") {
+		t.Fatalf("err = %v, want validate-order error for the request hash", err)
+	}
+}
+
+func TestRunnerVerifiesOrderIntegrity(t *testing.T) {
+	signerKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(signerKey.PublicKey).Hex()
+
+	tests := []struct {
+		name string
+		key  *ecdsa.PrivateKey
+		want string
+	}{
+		{"trusted signer", signerKey, ""},
+		{"untrusted signer", otherKey, "not the trusted signer"},
+		{"unsigned template", nil, "integritySignature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.Server.IntegrityKey = tt.key
+			env.cfg.IntegritySigner = signer
+
+			result, err := env.run()
+			if tt.want == "" {
+				if err != nil || result.Status != pipeline.StatusConfirmed {
+					t.Fatalf("Run = %+v, %v; want confirmed", result, err)
+				}
+				return
+			}
+			var se *pipeline.StageError
+			if !errors.As(err, &se) || se.Stage != pipeline.StageValidateOrder || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want validate-order error mentioning %q", err, tt.want)
+			}
+			if result.TxHash != "" {
+				t.Fatalf("tx %s sent for an order that failed verification", result.TxHash)
+			}
+		})
+	}
+}
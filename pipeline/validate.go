@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+// FeedFunctionName is the contract function every vote order must call.
+const FeedFunctionName = "feed"
+
+var ErrInvalidOrder = errors.New("order does not match the requested vote")
+
+// ValidateOrder checks the payment details returned by the API against the
+// vote that was requested, so nothing the API did not promise gets signed.
+// An empty job.AllowedContracts accepts any contract address.
+func ValidateOrder(order *api.OrderResponse, job VoteJob) error {
+	var problems []string
+	payment := order.Data.Payment
+
+	if payment.Params.CandidateID != job.CandidateID {
+		problems = append(problems, fmt.Sprintf("candidate ID is %q but %q was requested", payment.Params.CandidateID, job.CandidateID))
+	}
+	if payment.Params.FeedAmount != job.FeedAmount {
+		problems = append(problems, fmt.Sprintf("feed amount is %d but %d was requested", payment.Params.FeedAmount, job.FeedAmount))
+	}
+
+	if !common.IsHexAddress(payment.ContractAddress) {
+		problems = append(problems, fmt.Sprintf("contract address %q is not a valid address", payment.ContractAddress))
+	} else if len(job.AllowedContracts) > 0 && !containsAddress(job.AllowedContracts, payment.ContractAddress) {
+		problems = append(problems, fmt.Sprintf("contract %s is not on the allowlist for chain %d", common.HexToAddress(payment.ContractAddress).Hex(), job.ChainID))
+	}
+
+	if payment.FunctionName != FeedFunctionName {
+		problems = append(problems, fmt.Sprintf("function is %q but %q was expected", payment.FunctionName, FeedFunctionName))
+	}
+	if signature, ok := abiSignature(order, FeedFunctionName); !ok {
+		problems = append(problems, fmt.Sprintf("ABI has no %s function", FeedFunctionName))
+	} else if signature != wallet.FeedSignature {
+		problems = append(problems, fmt.Sprintf("ABI declares %s but %s is pinned", signature, wallet.FeedSignature))
+	}
+
+	params := payment.Params
+	if got, err := hexutil.Decode(ensureHexPrefix(params.UserHashedMessage)); err != nil || len(got) != common.HashLength {
+		problems = append(problems, fmt.Sprintf("userHashedMessage %q is not a 32-byte hash", params.UserHashedMessage))
+	} else if want := wallet.HashRequestData(params.RequestData); common.BytesToHash(got) != want {
+		problems = append(problems, fmt.Sprintf("userHashedMessage %s does not match the hash of requestData %s", params.UserHashedMessage, want.Hex()))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOrder, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func abiSignature(order *api.OrderResponse, name string) (string, bool) {
+	for _, entry := range order.Data.Payment.ABI {
+		if entry.Type != "function" || entry.Name != name {
+			continue
+		}
+		types := make([]string, len(entry.Inputs))
+		for i, input := range entry.Inputs {
+			types[i] = input.Type
+		}
+		return name + "(" + strings.Join(types, ",") + ")", true
+	}
+	return "", false
+}
+
+func containsAddress(addresses []string, address string) bool {
+	want := common.HexToAddress(address)
+	for _, a := range addresses {
+		if common.HexToAddress(a) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyIntegrity checks that the order's integritySignature was made by
+// job.IntegritySigner for this vote and voter. ValidateOrder has already
+// checked userHashedMessage against requestData.
+func VerifyIntegrity(order *api.OrderResponse, job VoteJob, voter string) error {
+	params := order.Data.Payment.Params
+
+	digest := wallet.IntegrityHash(job.CandidateID, job.FeedAmount, order.Data.Order.ID, wallet.HashRequestData(params.RequestData), common.HexToAddress(voter))
+	signer, err := wallet.RecoverIntegritySigner(digest, ensureHexPrefix(params.IntegritySignature))
+	if err != nil {
+		return fmt.Errorf("%w: integritySignature: %v", ErrInvalidOrder, err)
+	}
+	if common.HexToAddress(signer) != common.HexToAddress(job.IntegritySigner) {
+		return fmt.Errorf("%w: integritySignature was made by %s, not the trusted signer %s", ErrInvalidOrder, signer, common.HexToAddress(job.IntegritySigner).Hex())
+	}
+	return nil
+}
+
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") {
+		return s
+	}
+	return "0x" + s
+}
@@ -0,0 +1,73 @@
+package pipeline_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+func validOrder() (*api.OrderResponse, pipeline.VoteJob) {
+	var order api.OrderResponse
+	payment := &order.Data.Payment
+	payment.ContractAddress = "0x0000000000000000000000000000000000000AC1"
+	payment.FunctionName = pipeline.FeedFunctionName
+	payment.Params.CandidateID = "678dbb6579af53b8da5ddf3d"
+	payment.Params.FeedAmount = 2
+	payment.Params.RequestData = `{"candidateID":"678dbb6579af53b8da5ddf3d","feedAmount":2}`
+	payment.Params.UserHashedMessage = wallet.HashRequestData(payment.Params.RequestData).Hex()
+	json.Unmarshal([]byte(`[{"type":"function","name":"feed","inputs":[
+		{"type":"string"},{"type":"uint256"},{"type":"string"},{"type":"string"},{"type":"bytes"},{"type":"bytes"}]}]`), &payment.ABI)
+	job := pipeline.VoteJob{
+		ChainID:          10143,
+		CandidateID:      "678dbb6579af53b8da5ddf3d",
+		FeedAmount:       2,
+		AllowedContracts: []string{"0x0000000000000000000000000000000000000ac1"},
+	}
+	return &order, job
+}
+
+func TestValidateOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(*api.OrderResponse, *pipeline.VoteJob)
+		want   string
+	}{
+		{"valid", func(*api.OrderResponse, *pipeline.VoteJob) {}, ""},
+		{"empty allowlist", func(_ *api.OrderResponse, job *pipeline.VoteJob) { job.AllowedContracts = nil }, ""},
+		{"candidate", func(o *api.OrderResponse, _ *pipeline.VoteJob) { o.Data.Payment.Params.CandidateID = "other" }, "candidate ID"},
+		{"amount", func(o *api.OrderResponse, _ *pipeline.VoteJob) { o.Data.Payment.Params.FeedAmount = 200 }, "feed amount"},
+		{"contract", func(o *api.OrderResponse, _ *pipeline.VoteJob) {
+			o.Data.Payment.ContractAddress = "0x000000000000000000000000000000000000dEaD"
+		}, "allowlist"},
+		{"bad address", func(o *api.OrderResponse, _ *pipeline.VoteJob) { o.Data.Payment.ContractAddress = "feed" }, "not a valid address"},
+		{"function", func(o *api.OrderResponse, _ *pipeline.VoteJob) { o.Data.Payment.FunctionName = "transfer" }, `"transfer"`},
+		{"abi", func(o *api.OrderResponse, _ *pipeline.VoteJob) {
+			o.Data.Payment.ABI[0].Inputs = o.Data.Payment.ABI[0].Inputs[:2]
+		}, "feed(string,uint256)"},
+		{"request data", func(o *api.OrderResponse, _ *pipeline.VoteJob) { o.Data.Payment.Params.RequestData += " " }, "does not match the hash of requestData"},
+		{"hashed message", func(o *api.OrderResponse, _ *pipeline.VoteJob) { o.Data.Payment.Params.UserHashedMessage = "0x1111" }, "not a 32-byte hash"},
+		{"abi missing", func(o *api.OrderResponse, _ *pipeline.VoteJob) { o.Data.Payment.ABI = nil }, "ABI has no feed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, job := validOrder()
+			tt.tamper(order, &job)
+
+			err := pipeline.ValidateOrder(order, job)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("ValidateOrder: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, pipeline.ErrInvalidOrder) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want ErrInvalidOrder mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/checkpoint"
+)
+
+// handleShutdown turns the first SIGINT/SIGTERM into a request to stop
+// starting new votes, and cancels ctx once grace has passed so that votes
+// still in flight give up. A second signal cancels ctx immediately.
+func handleShutdown(grace time.Duration) (stopping <-chan struct{}, ctx context.Context) {
+	stop := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		slog.Warn("shutting down: no new votes will start, waiting for in-flight votes", "signal", sig.String(), "grace", grace)
+		close(stop)
+
+		select {
+		case <-time.After(grace):
+			slog.Warn("grace period over, abandoning in-flight votes")
+		case sig = <-signals:
+			slog.Warn("received second signal, abandoning in-flight votes", "signal", sig.String())
+		}
+		cancel()
+	}()
+
+	return stop, ctx
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func saveCheckpoint(store *checkpoint.Store, entry checkpoint.Entry) {
+	if err := store.Add(entry); err != nil {
+		slog.Error("failed to checkpoint unconfirmed order", "order_id", entry.OrderID, "tx_hash", entry.TxHash, "error", err)
+		return
+	}
+	slog.Warn("order not confirmed; saved for the resume command", "order_id", entry.OrderID, "tx_hash", entry.TxHash, "file", store.Path())
+}
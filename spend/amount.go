@@ -0,0 +1,48 @@
+package spend
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const decimals = 18
+
+// ParseAmount converts a decimal native-token amount such as "0.25" into wei.
+// An empty string means no limit and yields nil.
+func ParseAmount(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("amount %q has more than %d decimal places", s, decimals)
+	}
+
+	wei, ok := new(big.Int).SetString(whole+frac+strings.Repeat("0", decimals-len(frac)), 10)
+	if !ok || wei.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return wei, nil
+}
+
+// FormatAmount renders wei as a decimal native-token amount without trailing zeros.
+func FormatAmount(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+	s := wei.String()
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
@@ -0,0 +1,258 @@
+package spend
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/config"
+	"github.com/nekowawolf/aicraft-bot/history"
+)
+
+var ErrBudgetExceeded = errors.New("fee budget exceeded")
+
+// Limits are expressed in wei; a nil limit is unlimited. Daily limits reset at UTC midnight.
+type Limits struct {
+	WalletDaily *big.Int `json:"walletDaily,omitempty"`
+	WalletTotal *big.Int `json:"walletTotal,omitempty"`
+	GlobalDaily *big.Int `json:"globalDaily,omitempty"`
+	GlobalTotal *big.Int `json:"globalTotal,omitempty"`
+}
+
+func (l Limits) Enabled() bool {
+	return l.WalletDaily != nil || l.WalletTotal != nil || l.GlobalDaily != nil || l.GlobalTotal != nil
+}
+
+type usage struct {
+	address string
+	total   *big.Int
+	daily   map[string]*big.Int
+}
+
+func newUsage(address string) *usage {
+	return &usage{address: address, total: new(big.Int), daily: make(map[string]*big.Int)}
+}
+
+func (u *usage) add(day string, fee *big.Int) {
+	u.total.Add(u.total, fee)
+	if u.daily[day] == nil {
+		u.daily[day] = new(big.Int)
+	}
+	u.daily[day].Add(u.daily[day], fee)
+}
+
+func (u *usage) today(day string) *big.Int {
+	if v := u.daily[day]; v != nil {
+		return v
+	}
+	return new(big.Int)
+}
+
+// Tracker accounts for fees paid per wallet and globally and refuses new
+// votes whose estimated fee would push spending past the configured limits.
+// In-flight votes hold a reservation so concurrent workers cannot overshoot.
+// Until a fee has been seen there is no estimate to reserve, so votes then
+// wait for the one in flight to settle before reserving.
+type Tracker struct {
+	mu       sync.Mutex
+	settled  *sync.Cond
+	inFlight int
+	limits   Limits
+	wallets  map[string]*usage
+	global   *usage
+	reserved map[string]*big.Int
+	estimate *big.Int
+	now      func() time.Time
+}
+
+func NewTracker(limits Limits, records []history.Record) *Tracker {
+	t := &Tracker{
+		limits:   limits,
+		wallets:  make(map[string]*usage),
+		global:   newUsage(""),
+		reserved: make(map[string]*big.Int),
+		estimate: new(big.Int),
+		now:      time.Now,
+	}
+	t.settled = sync.NewCond(&t.mu)
+	// A resumed vote records its transaction again, so each fee is only
+	// counted for the first record of its transaction.
+	counted := make(map[string]bool)
+	for _, r := range records {
+		fee, ok := new(big.Int).SetString(r.FeePaid, 10)
+		if !ok || fee.Sign() <= 0 {
+			continue
+		}
+		if tx := strings.ToLower(r.TxHash); tx != "" {
+			if counted[tx] {
+				continue
+			}
+			counted[tx] = true
+		}
+		t.record(r.Wallet, dayOf(r.Timestamp), fee)
+	}
+	return t
+}
+
+func dayOf(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func (t *Tracker) wallet(address string) *usage {
+	key := strings.ToLower(address)
+	u, ok := t.wallets[key]
+	if !ok {
+		u = newUsage(address)
+		t.wallets[key] = u
+	}
+	return u
+}
+
+func (t *Tracker) record(address, day string, fee *big.Int) {
+	t.wallet(address).add(day, fee)
+	t.global.add(day, fee)
+	if fee.Cmp(t.estimate) > 0 {
+		t.estimate = new(big.Int).Set(fee)
+	}
+}
+
+// Reserve checks that one more vote from address fits the budget, using the
+// largest fee seen so far as the estimate. The returned settle function must
+// be called once with the actual fee paid: nil charges the estimate (fee
+// unknown), zero releases the reservation without spending. While no fee is
+// known Reserve blocks until no other reservation is in flight.
+func (t *Tracker) Reserve(address string) (func(fee *big.Int), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for t.limits.Enabled() && t.estimate.Sign() == 0 && t.inFlight > 0 {
+		t.settled.Wait()
+	}
+
+	day := dayOf(t.now())
+	key := strings.ToLower(address)
+	estimate := new(big.Int).Set(t.estimate)
+
+	walletReserved := new(big.Int)
+	if r := t.reserved[key]; r != nil {
+		walletReserved.Set(r)
+	}
+	globalReserved := new(big.Int)
+	for _, r := range t.reserved {
+		globalReserved.Add(globalReserved, r)
+	}
+
+	w := t.wallet(address)
+	checks := []struct {
+		name  string
+		limit *big.Int
+		spent *big.Int
+		held  *big.Int
+	}{
+		{"wallet daily", t.limits.WalletDaily, w.today(day), walletReserved},
+		{"wallet total", t.limits.WalletTotal, w.total, walletReserved},
+		{"global daily", t.limits.GlobalDaily, t.global.today(day), globalReserved},
+		{"global total", t.limits.GlobalTotal, t.global.total, globalReserved},
+	}
+	for _, c := range checks {
+		if c.limit == nil {
+			continue
+		}
+		projected := new(big.Int).Add(c.spent, c.held)
+		projected.Add(projected, estimate)
+		if projected.Cmp(c.limit) > 0 || (estimate.Sign() == 0 && c.spent.Cmp(c.limit) >= 0) {
+			return nil, fmt.Errorf("%w: %s budget %s, spent %s, in flight %s, next vote estimated at %s",
+				ErrBudgetExceeded, c.name, FormatAmount(c.limit), FormatAmount(c.spent), FormatAmount(c.held), FormatAmount(estimate))
+		}
+	}
+
+	if t.reserved[key] == nil {
+		t.reserved[key] = new(big.Int)
+	}
+	t.reserved[key].Add(t.reserved[key], estimate)
+	t.inFlight++
+
+	var once sync.Once
+	settle := func(fee *big.Int) {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			t.reserved[key].Sub(t.reserved[key], estimate)
+			t.inFlight--
+			t.settled.Broadcast()
+			if fee == nil {
+				fee = estimate
+			}
+			if fee.Sign() > 0 {
+				t.record(address, dayOf(t.now()), fee)
+			}
+		})
+	}
+	return settle, nil
+}
+
+type WalletSpend struct {
+	Wallet string   `json:"wallet"`
+	Today  *big.Int `json:"today"`
+	Total  *big.Int `json:"total"`
+}
+
+type Report struct {
+	Day     string        `json:"day"`
+	Wallets []WalletSpend `json:"wallets"`
+	Today   *big.Int      `json:"today"`
+	Total   *big.Int      `json:"total"`
+	Limits  Limits        `json:"limits"`
+}
+
+func (t *Tracker) Report() *Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	day := dayOf(t.now())
+	report := &Report{
+		Day:    day,
+		Today:  new(big.Int).Set(t.global.today(day)),
+		Total:  new(big.Int).Set(t.global.total),
+		Limits: t.limits,
+	}
+	for _, u := range t.wallets {
+		report.Wallets = append(report.Wallets, WalletSpend{
+			Wallet: u.address,
+			Today:  new(big.Int).Set(u.today(day)),
+			Total:  new(big.Int).Set(u.total),
+		})
+	}
+	sort.Slice(report.Wallets, func(i, j int) bool {
+		if c := report.Wallets[i].Total.Cmp(report.Wallets[j].Total); c != 0 {
+			return c > 0
+		}
+		return report.Wallets[i].Wallet < report.Wallets[j].Wallet
+	})
+	return report
+}
+
+func LimitsFromConfig(cfg *config.Config) (Limits, error) {
+	var limits Limits
+	var err error
+	for _, l := range []struct {
+		name   string
+		value  string
+		target **big.Int
+	}{
+		{"BUDGET_WALLET_DAILY", cfg.BudgetWalletDaily, &limits.WalletDaily},
+		{"BUDGET_WALLET_TOTAL", cfg.BudgetWalletTotal, &limits.WalletTotal},
+		{"BUDGET_GLOBAL_DAILY", cfg.BudgetGlobalDaily, &limits.GlobalDaily},
+		{"BUDGET_GLOBAL_TOTAL", cfg.BudgetGlobalTotal, &limits.GlobalTotal},
+	} {
+		if *l.target, err = ParseAmount(l.value); err != nil {
+			return Limits{}, fmt.Errorf("%s: %v", l.name, err)
+		}
+	}
+	return limits, nil
+}
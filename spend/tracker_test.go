@@ -0,0 +1,147 @@
+package spend
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/history"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+)
+
+func TestParseAndFormatAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		wei  string
+		back string
+	}{
+		{"1", "1000000000000000000", "1"},
+		{"0.25", "250000000000000000", "0.25"},
+		{".5", "500000000000000000", "0.5"},
+		{"0.000000000000000001", "1", "0.000000000000000001"},
+	}
+	for _, tt := range tests {
+		wei, err := ParseAmount(tt.in)
+		if err != nil {
+			t.Fatalf("ParseAmount(%q): %v", tt.in, err)
+		}
+		if wei.String() != tt.wei {
+			t.Fatalf("ParseAmount(%q) = %s, want %s", tt.in, wei, tt.wei)
+		}
+		if got := FormatAmount(wei); got != tt.back {
+			t.Fatalf("FormatAmount(%s) = %q, want %q", wei, got, tt.back)
+		}
+	}
+
+	if v, err := ParseAmount(""); v != nil || err != nil {
+		t.Fatalf("ParseAmount(\"\") = %v, %v; want nil, nil", v, err)
+	}
+	for _, bad := range []string{"abc", "-1", "0.0000000000000000001"} {
+		if _, err := ParseAmount(bad); err == nil {
+			t.Fatalf("ParseAmount(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestTrackerRefusesVotesOverBudget(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	records := []history.Record{
+		{Timestamp: now.Add(-48 * time.Hour), Wallet: "0xAAA", FeePaid: "40"},
+		{Timestamp: now.Add(-time.Hour), Wallet: "0xaaa", FeePaid: "30"},
+	}
+	tracker := NewTracker(Limits{WalletDaily: big.NewInt(100), WalletTotal: big.NewInt(120)}, records)
+	tracker.now = func() time.Time { return now }
+
+	settle, err := tracker.Reserve("0xAAA")
+	if err != nil {
+		t.Fatalf("first Reserve: %v", err)
+	}
+
+	if _, err := tracker.Reserve("0xAAA"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("second concurrent Reserve = %v, want ErrBudgetExceeded (40+30+40+40 > 120)", err)
+	}
+
+	settle(big.NewInt(10))
+	report := tracker.Report()
+	if report.Total.Int64() != 80 || report.Today.Int64() != 40 {
+		t.Fatalf("report = total %s today %s, want 80 and 40", report.Total, report.Today)
+	}
+
+	if _, err := tracker.Reserve("0xBBB"); err != nil {
+		t.Fatalf("other wallet Reserve: %v", err)
+	}
+}
+
+func TestTrackerSettleWithoutFee(t *testing.T) {
+	tracker := NewTracker(Limits{GlobalTotal: big.NewInt(100)}, []history.Record{{Timestamp: time.Now(), Wallet: "0xA", FeePaid: "50"}})
+
+	settle, err := tracker.Reserve("0xA")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	settle(nil)
+	settle(big.NewInt(1000))
+
+	if got := tracker.Report().Total.Int64(); got != 100 {
+		t.Fatalf("total = %d, want 100 (unknown fee charged at the 50 estimate, second settle ignored)", got)
+	}
+	if _, err := tracker.Reserve("0xA"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Reserve after exhausting budget = %v, want ErrBudgetExceeded", err)
+	}
+}
+
+func TestTrackerSerializesVotesUntilFirstFeeIsKnown(t *testing.T) {
+	tracker := NewTracker(Limits{GlobalTotal: big.NewInt(100)}, nil)
+
+	settle, err := tracker.Reserve("0xA")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := tracker.Reserve("0xB")
+		second <- err
+	}()
+	select {
+	case err := <-second:
+		t.Fatalf("second Reserve returned %v while the first vote, with no known fee, was in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	settle(big.NewInt(100))
+	select {
+	case err := <-second:
+		if !errors.Is(err, ErrBudgetExceeded) {
+			t.Fatalf("second Reserve = %v, want ErrBudgetExceeded once the first fee used the budget", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second Reserve still blocked after the first vote settled")
+	}
+}
+
+func TestTrackerCountsResumedTransactionOnce(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	failed := pipeline.VoteResult{
+		WalletAddress: "0xAAA",
+		OrderID:       "order-1",
+		TxHash:        "0x01",
+		EffectiveFee:  "30",
+		Stage:         pipeline.StageConfirmOrder,
+		Status:        pipeline.StatusFailed,
+	}
+	resumed := failed
+	resumed.Stage, resumed.Status = "", pipeline.StatusConfirmed
+	records := []history.Record{
+		history.FromResult(failed, now.Add(-time.Hour)),
+		history.FromResult(resumed, now.Add(-time.Minute)),
+		{Timestamp: now, Wallet: "0xAAA", TxHash: "0x02", FeePaid: "5"},
+	}
+
+	tracker := NewTracker(Limits{}, records)
+	tracker.now = func() time.Time { return now }
+	if report := tracker.Report(); report.Total.Int64() != 35 || report.Wallets[0].Total.Int64() != 35 {
+		t.Fatalf("spent = %s (wallet %s), want 35 with the resumed fee counted once", report.Total, report.Wallets[0].Total)
+	}
+}
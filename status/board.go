@@ -0,0 +1,143 @@
+package status
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/history"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+)
+
+const DefaultResults = 20
+
+const StateIdle = "idle"
+
+// WalletState is the last pipeline event seen for a wallet. State is the
+// event kind, or idle once the wallet's vote has finished.
+type WalletState struct {
+	Address   string         `json:"address"`
+	Stage     pipeline.Stage `json:"stage,omitempty"`
+	State     string         `json:"state"`
+	Since     time.Time      `json:"since"`
+	Confirmed int            `json:"confirmed"`
+	Failed    int            `json:"failed"`
+	LastError string         `json:"lastError,omitempty"`
+}
+
+type Snapshot struct {
+	StartedAt time.Time        `json:"startedAt"`
+	Now       time.Time        `json:"now"`
+	Wallets   []WalletState    `json:"wallets"`
+	Results   []history.Record `json:"results"`
+}
+
+// Board keeps the current stage of every wallet's pipeline and the most
+// recent results, fed from runner hooks.
+type Board struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	started time.Time
+	limit   int
+	wallets map[string]*WalletState
+	order   []string
+	results []history.Record
+}
+
+func NewBoard(limit int) *Board {
+	if limit < 1 {
+		limit = DefaultResults
+	}
+	return &Board{
+		now:     time.Now,
+		started: time.Now(),
+		limit:   limit,
+		wallets: make(map[string]*WalletState),
+	}
+}
+
+// Track lists a wallet as idle before it has run anything.
+func (b *Board) Track(address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.wallet(address)
+}
+
+func (b *Board) wallet(address string) *WalletState {
+	ws, ok := b.wallets[address]
+	if !ok {
+		ws = &WalletState{Address: address, State: StateIdle, Since: b.now()}
+		b.wallets[address] = ws
+		b.order = append(b.order, address)
+	}
+	return ws
+}
+
+// ObserveEvent is a pipeline.Hook.
+func (b *Board) ObserveEvent(ev pipeline.Event) {
+	if ev.Result == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ws := b.wallet(ev.Result.WalletAddress)
+	ws.Stage = ev.Stage
+	ws.State = string(ev.Kind)
+	ws.Since = b.now()
+}
+
+func (b *Board) ObserveResult(result pipeline.VoteResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	ws := b.wallet(result.WalletAddress)
+	ws.Stage = result.Stage
+	ws.State = StateIdle
+	ws.Since = now
+	if result.Status == pipeline.StatusConfirmed {
+		ws.Confirmed++
+		ws.LastError = ""
+	} else {
+		ws.Failed++
+		ws.LastError = result.Error
+	}
+
+	b.results = append(b.results, history.FromResult(result, now))
+	if len(b.results) > b.limit {
+		b.results = b.results[len(b.results)-b.limit:]
+	}
+}
+
+// Snapshot returns wallets in the order they were first seen and results
+// newest first.
+func (b *Board) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snap := Snapshot{StartedAt: b.started, Now: b.now()}
+	for _, address := range b.order {
+		snap.Wallets = append(snap.Wallets, *b.wallets[address])
+	}
+	for i := len(b.results) - 1; i >= 0; i-- {
+		snap.Results = append(snap.Results, b.results[i])
+	}
+	return snap
+}
+
+// Stuck returns the wallets that entered a stage more than after ago and
+// have not left it since.
+func (b *Board) Stuck(after time.Duration) []WalletState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var stuck []WalletState
+	now := b.now()
+	for _, address := range b.order {
+		ws := b.wallets[address]
+		if ws.State == string(pipeline.EventStarted) && now.Sub(ws.Since) > after {
+			stuck = append(stuck, *ws)
+		}
+	}
+	return stuck
+}
@@ -0,0 +1,105 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/api"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+const (
+	CheckRPC     = "rpc"
+	CheckChainID = "chain-id"
+	CheckAPI     = "api"
+	CheckToken   = "token"
+
+	checkTimeout = 10 * time.Second
+)
+
+// Check is one readiness check. Error is a short fixed description; the
+// underlying error, which may contain RPC or API URLs, is only logged.
+type Check struct {
+	Name   string `json:"name"`
+	Wallet string `json:"wallet,omitempty"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Readiness struct {
+	Ready  bool    `json:"ready"`
+	Checks []Check `json:"checks"`
+}
+
+// Checker decides whether the bot can currently vote: the RPC endpoint
+// answers on the configured chain, the API answers, and no wallet's latest
+// sign-in failed. It never signs in itself, so that a probe cannot replace a
+// sign-in message the pipeline is about to sign; sign-in outcomes come from
+// the pipeline through ObserveEvent.
+type Checker struct {
+	Wallets []*wallet.Wallet
+	API     *api.Client
+	RPCURL  string
+	ChainID int64
+
+	mu      sync.Mutex
+	signIns map[string]error
+}
+
+// ObserveEvent is a pipeline.Hook that records each wallet's latest sign-in
+// outcome.
+func (c *Checker) ObserveEvent(ev pipeline.Event) {
+	if ev.Stage != pipeline.StageSignIn || ev.Kind == pipeline.EventStarted || ev.Result == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.signIns == nil {
+		c.signIns = make(map[string]error)
+	}
+	c.signIns[strings.ToLower(ev.Result.WalletAddress)] = ev.Err
+}
+
+func (c *Checker) Check(ctx context.Context) *Readiness {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	health := c.Wallets[0].CheckHealth(ctx, c.RPCURL, c.ChainID)
+	rpc := Check{Name: CheckRPC, OK: health.RPCReachable}
+	chain := Check{Name: CheckChainID, OK: health.RPCReachable && health.ChainIDMatches}
+	switch {
+	case !health.RPCReachable:
+		slog.Warn("readiness: RPC unreachable", "error", health.Err())
+		rpc.Error = "RPC unreachable"
+		chain.Error = "RPC unreachable"
+	case !health.ChainIDMatches:
+		chain.Error = fmt.Sprintf("RPC reports chain ID %d but %d is configured", health.ChainID, c.ChainID)
+	}
+
+	apiCheck := Check{Name: CheckAPI, OK: true}
+	if err := c.API.Ping(); err != nil {
+		slog.Warn("readiness: API unreachable", "error", err)
+		apiCheck.OK = false
+		apiCheck.Error = "API unreachable"
+	}
+
+	r := &Readiness{Checks: []Check{rpc, chain, apiCheck}}
+	r.Ready = rpc.OK && chain.OK && apiCheck.OK
+	c.mu.Lock()
+	for _, w := range c.Wallets {
+		token := Check{Name: CheckToken, Wallet: w.GetAddress(), OK: true}
+		if err := c.signIns[strings.ToLower(w.GetAddress())]; err != nil {
+			token.OK = false
+			token.Error = "latest sign-in failed"
+		}
+		r.Checks = append(r.Checks, token)
+		r.Ready = r.Ready && token.OK
+	}
+	c.mu.Unlock()
+	return r
+}
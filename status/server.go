@@ -0,0 +1,101 @@
+package status
+
+import (
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultStuckAfter is comfortably longer than the wallet's receipt timeout,
+// so a stage running this long means the pipeline is hung.
+const DefaultStuckAfter = 10 * time.Minute
+
+type Server struct {
+	Board      *Board
+	Checker    *Checker
+	StuckAfter time.Duration
+}
+
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", s.healthz)
+	mux.HandleFunc("/readyz", s.readyz)
+	mux.HandleFunc("/status", s.status)
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	after := s.StuckAfter
+	if after <= 0 {
+		after = DefaultStuckAfter
+	}
+	if stuck := s.Board.Stuck(after); len(stuck) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "stuck", "wallets": stuck})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	readiness := s.Checker.Check(r.Context())
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, readiness)
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	snap := s.Board.Snapshot()
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		writeJSON(w, http.StatusOK, snap)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusPage.Execute(w, snap); err != nil {
+		slog.Warn("failed to render status page", "error", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write response", "error", err)
+	}
+}
+
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"ago": func(now, t time.Time) string { return now.Sub(t).Round(time.Second).String() },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="5">
+<title>AICraft bot status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; font-family: monospace; }
+.failed { color: #b00; }
+.confirmed { color: #070; }
+</style>
+</head>
+<body>
+<h1>AICraft bot</h1>
+<p>Up since {{.StartedAt.Format "2006-01-02 15:04:05 MST"}} ({{ago .Now .StartedAt}})</p>
+<h2>Wallets</h2>
+<table>
+<tr><th>Wallet</th><th>Stage</th><th>State</th><th>For</th><th>Confirmed</th><th>Failed</th><th>Last error</th></tr>
+{{range .Wallets}}<tr><td>{{.Address}}</td><td>{{.Stage}}</td><td>{{.State}}</td><td>{{ago $.Now .Since}}</td><td>{{.Confirmed}}</td><td>{{.Failed}}</td><td class="failed">{{.LastError}}</td></tr>
+{{end}}</table>
+<h2>Recent results</h2>
+<table>
+<tr><th>Time</th><th>Wallet</th><th>Status</th><th>Stage</th><th>Order</th><th>Tx hash</th><th>Fee (wei)</th></tr>
+{{range .Results}}<tr><td>{{.Timestamp.Format "15:04:05"}}</td><td>{{.Wallet}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Stage}}</td><td>{{.OrderID}}</td><td>{{.TxHash}}</td><td>{{.FeePaid}}</td></tr>
+{{else}}<tr><td colspan="7">No votes yet</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,177 @@
+package status_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nekowawolf/aicraft-bot/apitest"
+	"github.com/nekowawolf/aicraft-bot/chaintest"
+	"github.com/nekowawolf/aicraft-bot/pipeline"
+	"github.com/nekowawolf/aicraft-bot/status"
+)
+
+type testEnv struct {
+	*chaintest.Env
+	board   *status.Board
+	checker *status.Checker
+	mux     *http.ServeMux
+}
+
+func newTestEnv(t *testing.T, extraKeys ...string) *testEnv {
+	t.Helper()
+
+	env := &testEnv{Env: chaintest.NewEnv(t, extraKeys...), board: status.NewBoard(2), mux: http.NewServeMux()}
+	env.checker = &status.Checker{
+		Wallets: env.Wallets,
+		API:     env.Server.Client(),
+		RPCURL:  "simulated://chain",
+		ChainID: chaintest.ChainID,
+	}
+	s := &status.Server{Board: env.board, Checker: env.checker}
+	s.Register(env.mux)
+	return env
+}
+
+func (e *testEnv) get(t *testing.T, path string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	if v != nil {
+		if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+			t.Fatalf("GET %s: decode: %v", path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestReadyzChecksRPCAndAPI(t *testing.T) {
+	env := newTestEnv(t)
+
+	var ready status.Readiness
+	if code := env.get(t, "/readyz", &ready); code != http.StatusOK || !ready.Ready {
+		t.Fatalf("GET /readyz = %d %+v, want ready", code, ready)
+	}
+	if n := env.Server.Requests(apitest.RouteSignInMessage) + env.Server.Requests(apitest.RouteSignIn); n != 0 {
+		t.Fatalf("readiness made %d sign-in requests, want none", n)
+	}
+
+	env.Server.Close()
+	rec := httptest.NewRecorder()
+	env.mux.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || strings.Contains(rec.Body.String(), env.Server.URL) {
+		t.Fatalf("GET /readyz = %d %s, want not ready without the API URL", rec.Code, rec.Body.String())
+	}
+	json.NewDecoder(rec.Body).Decode(&ready)
+	for _, c := range ready.Checks {
+		if want := c.Name != status.CheckAPI; c.OK != want {
+			t.Errorf("check %s ok = %t, want %t", c.Name, c.OK, want)
+		}
+	}
+}
+
+func TestReadyzReportsFailedSignInPerWallet(t *testing.T) {
+	env := newTestEnv(t, "0x8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63")
+	failing := env.Wallets[1]
+
+	env.Server.Fail(apitest.RouteSignIn, 1, http.StatusUnauthorized, map[string]string{"message": "bad signature"})
+	runner := pipeline.NewRunner(failing, env.Server.Client())
+	runner.OnEvent(env.checker.ObserveEvent)
+	if _, err := runner.Run(context.Background(), pipeline.VoteJob{RPCURL: "simulated://chain", ChainID: chaintest.ChainID, CandidateID: "678dbb6579af53b8da5ddf3d", FeedAmount: 1}); err == nil {
+		t.Fatal("Run succeeded despite the failed sign-in")
+	}
+
+	var ready status.Readiness
+	if code := env.get(t, "/readyz", &ready); code != http.StatusServiceUnavailable || ready.Ready {
+		t.Fatalf("GET /readyz = %d %+v, want not ready", code, ready)
+	}
+	var tokens int
+	for _, c := range ready.Checks {
+		if c.Name != status.CheckToken {
+			continue
+		}
+		tokens++
+		if want := c.Wallet != failing.GetAddress(); c.OK != want {
+			t.Errorf("token check for %s ok = %t, want %t", c.Wallet, c.OK, want)
+		}
+	}
+	if tokens != 2 {
+		t.Fatalf("got %d token checks, want one per wallet", tokens)
+	}
+}
+
+func TestStatusReportsStagesAndRecentResults(t *testing.T) {
+	env := newTestEnv(t)
+	address := env.Wallet.GetAddress()
+	env.board.Track(address)
+
+	var started pipeline.Stage
+	runner := pipeline.NewRunner(env.Wallet, env.Server.Client())
+	runner.OnEvent(env.board.ObserveEvent)
+	runner.OnEvent(func(ev pipeline.Event) {
+		if ev.Kind == pipeline.EventStarted && ev.Stage == pipeline.StageWaitReceipt {
+			snap := env.board.Snapshot()
+			started = snap.Wallets[0].Stage
+		}
+	})
+
+	job := pipeline.VoteJob{
+		RPCURL:          "simulated://chain",
+		ChainID:         chaintest.ChainID,
+		WalletID:        "test-wallet",
+		CandidateID:     "678dbb6579af53b8da5ddf3d",
+		TargetCountryID: "VN",
+		FeedAmount:      1,
+	}
+	for i := 0; i < 3; i++ {
+		result, _ := runner.Run(context.Background(), job)
+		env.board.ObserveResult(result)
+	}
+	if started != pipeline.StageWaitReceipt {
+		t.Fatalf("stage while waiting = %q, want %q", started, pipeline.StageWaitReceipt)
+	}
+
+	var snap status.Snapshot
+	if code := env.get(t, "/status?format=json", &snap); code != http.StatusOK {
+		t.Fatalf("GET /status = %d", code)
+	}
+	if len(snap.Wallets) != 1 || snap.Wallets[0].Confirmed != 3 || snap.Wallets[0].State != status.StateIdle {
+		t.Fatalf("wallets = %+v, want one idle wallet with 3 confirmed votes", snap.Wallets)
+	}
+	if len(snap.Results) != 2 || snap.Results[0].Timestamp.Before(snap.Results[1].Timestamp) {
+		t.Fatalf("results = %+v, want the 2 newest first", snap.Results)
+	}
+
+	rec := httptest.NewRecorder()
+	env.mux.ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+	if !strings.Contains(rec.Header().Get("Content-Type"), "text/html") || !strings.Contains(rec.Body.String(), address) {
+		t.Fatalf("GET /status did not render an HTML page listing %s", address)
+	}
+}
+
+func TestHealthzReportsStuckWallets(t *testing.T) {
+	board := status.NewBoard(0)
+	s := &status.Server{Board: board, StuckAfter: 20 * time.Millisecond}
+	mux := http.NewServeMux()
+	s.Register(mux)
+
+	result := &pipeline.VoteResult{WalletAddress: "0xabc"}
+	board.ObserveEvent(pipeline.Event{Kind: pipeline.EventStarted, Stage: pipeline.StageWaitReceipt, Result: result})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /healthz = %d, want 200 while the stage is fresh", rec.Code)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), string(pipeline.StageWaitReceipt)) {
+		t.Fatalf("GET /healthz = %d %s, want 503 naming the stuck stage", rec.Code, rec.Body)
+	}
+}
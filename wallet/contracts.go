@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrContractNotAllowed = errors.New("contract is not on the allowlist")
+	ErrCodeHashMismatch   = errors.New("contract code hash mismatch")
+)
+
+// ContractPolicy restricts which contracts the wallet signs transactions to.
+// An empty policy is unrestricted; once any contract is allowed, chains
+// without an allowed contract are refused.
+type ContractPolicy struct {
+	contracts map[int64]map[common.Address]common.Hash
+}
+
+func NewContractPolicy() *ContractPolicy {
+	return &ContractPolicy{contracts: make(map[int64]map[common.Address]common.Hash)}
+}
+
+// Allow permits transactions to address on chainID. A non-zero codeHash also
+// pins the keccak256 hash of the contract's runtime bytecode.
+func (p *ContractPolicy) Allow(chainID int64, address common.Address, codeHash common.Hash) {
+	if p.contracts[chainID] == nil {
+		p.contracts[chainID] = make(map[common.Address]common.Hash)
+	}
+	p.contracts[chainID][address] = codeHash
+}
+
+func (w *Wallet) SetContractPolicy(p *ContractPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.policy = p
+}
+
+// CheckContract enforces the contract policy for a transaction to address,
+// fetching its code with eth_getCode so that an address without a contract
+// or with unexpected bytecode is refused.
+func (w *Wallet) CheckContract(ctx context.Context, rpcURL string, chainID int64, address common.Address) error {
+	w.mu.Lock()
+	policy := w.policy
+	w.mu.Unlock()
+	if policy == nil || len(policy.contracts) == 0 {
+		return nil
+	}
+
+	pinned, ok := policy.contracts[chainID][address]
+	if !ok {
+		return fmt.Errorf("%w: %s on chain %d", ErrContractNotAllowed, address.Hex(), chainID)
+	}
+
+	client, err := w.client(ctx, rpcURL)
+	if err != nil {
+		return err
+	}
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get contract code: %v", err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("no contract code at %s", address.Hex())
+	}
+
+	codeHash := crypto.Keccak256Hash(code)
+	slog.Debug("checked contract code", "contract", address.Hex(), "code_hash", codeHash.Hex())
+	if pinned != (common.Hash{}) && codeHash != pinned {
+		return fmt.Errorf("%w: %s has code hash %s, expected %s", ErrCodeHashMismatch, address.Hex(), codeHash.Hex(), pinned.Hex())
+	}
+	return nil
+}
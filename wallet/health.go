@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type HealthReport struct {
+	RPCURL          string   `json:"rpcUrl"`
+	RPCReachable    bool     `json:"rpcReachable"`
+	ExpectedChainID int64    `json:"expectedChainId"`
+	ChainID         int64    `json:"chainId,omitempty"`
+	ChainIDMatches  bool     `json:"chainIdMatches"`
+	BlockNumber     uint64   `json:"blockNumber,omitempty"`
+	Address         string   `json:"address"`
+	Balance         *big.Int `json:"balance,omitempty"`
+	Problems        []string `json:"problems,omitempty"`
+}
+
+func (r *HealthReport) Healthy() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *HealthReport) Err() error {
+	if r.Healthy() {
+		return nil
+	}
+	err := errors.New(strings.Join(r.Problems, "; "))
+	if r.RPCReachable && !r.ChainIDMatches {
+		return fmt.Errorf("%w: %v", ErrChainIDMismatch, err)
+	}
+	return err
+}
+
+func (w *Wallet) CheckHealth(ctx context.Context, rpcURL string, expectedChainID int64) *HealthReport {
+	report := &HealthReport{
+		RPCURL:          rpcURL,
+		ExpectedChainID: expectedChainID,
+		Address:         w.GetAddress(),
+	}
+
+	c, err := w.client(ctx, rpcURL)
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+		return report
+	}
+
+	chainID, err := w.ChainID(ctx, rpcURL)
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+		return report
+	}
+	report.RPCReachable = true
+	report.ChainID = chainID
+	report.ChainIDMatches = chainID == expectedChainID
+	if !report.ChainIDMatches {
+		report.Problems = append(report.Problems, fmt.Sprintf("RPC reports chain ID %d but %d is configured", chainID, expectedChainID))
+	}
+
+	block, err := c.BlockNumber(ctx)
+	if err != nil {
+		// The chain ID may have come from the cache, so this is the first
+		// request that actually proves the endpoint is still up.
+		report.RPCReachable = false
+		report.Problems = append(report.Problems, fmt.Sprintf("failed to get block number: %v", err))
+	} else {
+		report.BlockNumber = block
+	}
+
+	balance, err := c.BalanceAt(ctx, common.HexToAddress(report.Address), nil)
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("failed to get balance: %v", err))
+	} else {
+		report.Balance = balance
+	}
+
+	return report
+}
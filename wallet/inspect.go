@@ -0,0 +1,209 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// FeedABI describes the feed function prepareVoteData encodes. The events
+// of the real feed contract are not known, so it declares none and logs stay
+// undecoded unless another ABI describes them.
+const FeedABI = `[
+	{"type":"function","name":"feed","stateMutability":"payable","outputs":[],"inputs":[
+		{"name":"candidateID","type":"string"},
+		{"name":"feedAmount","type":"uint256"},
+		{"name":"requestID","type":"string"},
+		{"name":"requestData","type":"string"},
+		{"name":"userHashedMessage","type":"bytes"},
+		{"name":"integritySignature","type":"bytes"}]}
+]`
+
+var ErrNotFeedCall = errors.New("calldata is not a feed call")
+
+// VoteCall holds the arguments of a feed call.
+type VoteCall struct {
+	CandidateID        string        `json:"candidateId"`
+	FeedAmount         *big.Int      `json:"feedAmount"`
+	RequestID          string        `json:"requestId"`
+	RequestData        string        `json:"requestData"`
+	UserHashedMessage  hexutil.Bytes `json:"userHashedMessage"`
+	IntegritySignature hexutil.Bytes `json:"integritySignature"`
+}
+
+// DecodeVoteData is the inverse of prepareVoteData.
+func DecodeVoteData(data []byte) (*VoteCall, error) {
+	selector := crypto.Keccak256([]byte(FeedSignature))[:4]
+	if len(data) < 4 || !bytes.Equal(data[:4], selector) {
+		return nil, ErrNotFeedCall
+	}
+	feed, err := ParseABI(FeedABI)
+	if err != nil {
+		return nil, err
+	}
+	values, err := feed.Methods["feed"].Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode feed arguments: %v", err)
+	}
+	return &VoteCall{
+		CandidateID:        values[0].(string),
+		FeedAmount:         values[1].(*big.Int),
+		RequestID:          values[2].(string),
+		RequestData:        values[3].(string),
+		UserHashedMessage:  values[4].([]byte),
+		IntegritySignature: values[5].([]byte),
+	}, nil
+}
+
+func ParseABI(definition string) (abi.ABI, error) {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("invalid contract ABI: %v", err)
+	}
+	return parsed, nil
+}
+
+// DecodedLog is a receipt log, with Event and Fields filled in when the ABI
+// knows its signature.
+type DecodedLog struct {
+	Index   uint                   `json:"index"`
+	Address string                 `json:"address"`
+	Event   string                 `json:"event,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Topics  []common.Hash          `json:"topics"`
+	Data    hexutil.Bytes          `json:"data"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+func DecodeLog(contractABI abi.ABI, log *types.Log) DecodedLog {
+	decoded := DecodedLog{Index: log.Index, Address: log.Address.Hex(), Topics: log.Topics, Data: log.Data}
+	if len(log.Topics) == 0 {
+		return decoded
+	}
+	event, err := contractABI.EventByID(log.Topics[0])
+	if err != nil {
+		return decoded
+	}
+	decoded.Event = event.Sig
+
+	fields := make(map[string]interface{})
+	if err := contractABI.UnpackIntoMap(fields, event.Name, log.Data); err != nil {
+		decoded.Error = fmt.Sprintf("failed to decode data: %v", err)
+		return decoded
+	}
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(fields, indexed, log.Topics[1:]); err != nil {
+		decoded.Error = fmt.Sprintf("failed to decode topics: %v", err)
+		return decoded
+	}
+	for name, value := range fields {
+		switch v := value.(type) {
+		case []byte:
+			fields[name] = hexutil.Bytes(v)
+		case [32]byte:
+			fields[name] = common.Hash(v)
+		}
+	}
+	decoded.Fields = fields
+	return decoded
+}
+
+// TxInspection describes a transaction and, once mined, its receipt. Amounts
+// are in wei.
+type TxInspection struct {
+	Hash      string `json:"hash"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Nonce     uint64 `json:"nonce"`
+	ChainID   int64  `json:"chainId"`
+	Value     string `json:"value"`
+	Pending   bool   `json:"pending"`
+	GasLimit  uint64 `json:"gasLimit"`
+	GasTipCap string `json:"gasTipCap"`
+	GasFeeCap string `json:"gasFeeCap"`
+
+	Status            string `json:"status,omitempty"`
+	BlockNumber       uint64 `json:"blockNumber,omitempty"`
+	GasUsed           uint64 `json:"gasUsed,omitempty"`
+	EffectiveGasPrice string `json:"effectiveGasPrice,omitempty"`
+	Fee               string `json:"fee,omitempty"`
+
+	Call      *VoteCall    `json:"call,omitempty"`
+	CallError string       `json:"callError,omitempty"`
+	Logs      []DecodedLog `json:"logs"`
+}
+
+// InspectTransaction loads txHash and its receipt from client and decodes
+// the feed call and any logs contractABI describes.
+func InspectTransaction(ctx context.Context, client EthClient, txHash common.Hash, contractABI abi.ABI) (*TxInspection, error) {
+	tx, pending, err := client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %v", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover sender: %v", err)
+	}
+
+	ins := &TxInspection{
+		Hash:      tx.Hash().Hex(),
+		From:      from.Hex(),
+		Nonce:     tx.Nonce(),
+		ChainID:   tx.ChainId().Int64(),
+		Value:     tx.Value().String(),
+		Pending:   pending,
+		GasLimit:  tx.Gas(),
+		GasTipCap: tx.GasTipCap().String(),
+		GasFeeCap: tx.GasFeeCap().String(),
+		Logs:      []DecodedLog{},
+	}
+	if tx.To() != nil {
+		ins.To = tx.To().Hex()
+	}
+	if ins.Call, err = DecodeVoteData(tx.Data()); err != nil {
+		ins.CallError = err.Error()
+	}
+	if pending {
+		return ins, nil
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		ins.Pending = true
+		return ins, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipt: %v", err)
+	}
+	ins.Status = "success"
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		ins.Status = "reverted"
+	}
+	if receipt.BlockNumber != nil {
+		ins.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	ins.GasUsed = receipt.GasUsed
+	if receipt.EffectiveGasPrice != nil {
+		ins.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		ins.Fee = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice).String()
+	}
+	for _, log := range receipt.Logs {
+		ins.Logs = append(ins.Logs, DecodeLog(contractABI, log))
+	}
+	return ins, nil
+}
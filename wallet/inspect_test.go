@@ -0,0 +1,59 @@
+package wallet_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nekowawolf/aicraft-bot/chaintest"
+	"github.com/nekowawolf/aicraft-bot/wallet"
+)
+
+func TestInspectTransactionDecodesVote(t *testing.T) {
+	env := chaintest.NewEnv(t)
+	w := env.Wallet
+
+	txHash, err := w.CreateVoteTransaction("simulated://chain", chaintest.FeedStubAddress.Hex(), "678dbb6579af53b8da5ddf3d", 3, chaintest.ChainID, "order-1", `{"candidateID":"678dbb6579af53b8da5ddf3d"}`, "0x1111", "0x2222")
+	if err != nil {
+		t.Fatalf("CreateVoteTransaction: %v", err)
+	}
+	receipt, err := w.WaitForTransactionReceiptContext(context.Background(), "simulated://chain", txHash)
+	if err != nil {
+		t.Fatalf("WaitForTransactionReceipt: %v", err)
+	}
+
+	feed, err := wallet.ParseABI(wallet.FeedABI)
+	if err != nil {
+		t.Fatalf("ParseABI: %v", err)
+	}
+	ins, err := wallet.InspectTransaction(context.Background(), env.Chain.Backend.Client(), common.HexToHash(txHash), feed)
+	if err != nil {
+		t.Fatalf("InspectTransaction: %v", err)
+	}
+	if len(ins.Logs) != 1 || ins.Logs[0].Event != "" {
+		t.Fatalf("logs = %+v, want the stub's log left undecoded by the feed ABI", ins.Logs)
+	}
+
+	events, err := wallet.ParseABI(chaintest.FedEventABI)
+	if err != nil {
+		t.Fatalf("ParseABI: %v", err)
+	}
+	if ins, err = wallet.InspectTransaction(context.Background(), env.Chain.Backend.Client(), common.HexToHash(txHash), events); err != nil {
+		t.Fatalf("InspectTransaction: %v", err)
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	if ins.Pending || ins.Status != "success" || ins.From != w.GetAddress() || ins.Fee != fee.String() {
+		t.Fatalf("inspection = %+v, want the confirmed vote from %s paying %s", ins, w.GetAddress(), fee)
+	}
+	if ins.Call == nil || ins.Call.CandidateID != "678dbb6579af53b8da5ddf3d" || ins.Call.FeedAmount.Int64() != 3 || ins.Call.RequestID != "order-1" {
+		t.Fatalf("call = %+v (%s)", ins.Call, ins.CallError)
+	}
+	if len(ins.Logs) != 1 || ins.Logs[0].Event != "Fed(address,uint256)" {
+		t.Fatalf("logs = %+v, want one Fed event", ins.Logs)
+	}
+	if voter := ins.Logs[0].Fields["voter"].(common.Address); voter != common.HexToAddress(w.GetAddress()) {
+		t.Fatalf("Fed voter = %s, want %s", voter.Hex(), w.GetAddress())
+	}
+}
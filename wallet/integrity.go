@@ -0,0 +1,39 @@
+package wallet
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// HashRequestData returns the userHashedMessage an order must carry for its
+// requestData: keccak256 of the raw requestData string.
+func HashRequestData(requestData string) common.Hash {
+	return crypto.Keccak256Hash([]byte(requestData))
+}
+
+// IntegrityHash returns the digest the API's integrity key is assumed to sign
+// with personal_sign to bind an order to one voter:
+//
+//	keccak256(abi.encodePacked(candidateID, uint256(feedAmount), requestID, userHashedMessage, voter))
+//
+// The API does not document its scheme; this layout is a guess that has not
+// been checked against a real order. If every order fails integrity
+// verification with an unexpected signer, the API signs something else and
+// INTEGRITY_SIGNER should be left unset until this is corrected.
+func IntegrityHash(candidateID string, feedAmount int, requestID string, userHashedMessage common.Hash, voter common.Address) common.Hash {
+	return crypto.Keccak256Hash(
+		[]byte(candidateID),
+		common.LeftPadBytes(big.NewInt(int64(feedAmount)).Bytes(), 32),
+		[]byte(requestID),
+		userHashedMessage.Bytes(),
+		voter.Bytes(),
+	)
+}
+
+// RecoverIntegritySigner returns the address that signed digest, as produced
+// by IntegrityHash, with personal_sign.
+func RecoverIntegritySigner(digest common.Hash, signature string) (string, error) {
+	return RecoverAddress(string(digest.Bytes()), signature)
+}
@@ -0,0 +1,178 @@
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RPCObserver is told about every RPC call made through an observed dialer,
+// keyed by the JSON-RPC method name.
+type RPCObserver func(method string, elapsed time.Duration, err error)
+
+// ObserveDialer wraps dial so that every call on the clients it returns is
+// reported to observe.
+func ObserveDialer(dial Dialer, observe RPCObserver) Dialer {
+	return interceptDialer(dial, func(ctx context.Context, method string, call func() error) error {
+		start := time.Now()
+		err := call()
+		observe(method, time.Since(start), err)
+		return err
+	})
+}
+
+type interceptor func(ctx context.Context, method string, call func() error) error
+
+func interceptDialer(dial Dialer, intercept interceptor) Dialer {
+	if dial == nil {
+		dial = DialRPC
+	}
+	return func(ctx context.Context, rpcURL string) (EthClient, error) {
+		client, err := dial(ctx, rpcURL)
+		if err != nil {
+			return nil, err
+		}
+		return &interceptedClient{client: client, intercept: intercept}, nil
+	}
+}
+
+type interceptedClient struct {
+	client    EthClient
+	intercept interceptor
+}
+
+func (c *interceptedClient) Close() {
+	if closer, ok := c.client.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+func (c *interceptedClient) ChainID(ctx context.Context) (v *big.Int, err error) {
+	err = c.intercept(ctx, "eth_chainId", func() error {
+		v, err = c.client.ChainID(ctx)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) BlockNumber(ctx context.Context) (v uint64, err error) {
+	err = c.intercept(ctx, "eth_blockNumber", func() error {
+		v, err = c.client.BlockNumber(ctx)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (v *big.Int, err error) {
+	err = c.intercept(ctx, "eth_getBalance", func() error {
+		v, err = c.client.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) (v []byte, err error) {
+	err = c.intercept(ctx, "eth_getStorageAt", func() error {
+		v, err = c.client.StorageAt(ctx, account, key, blockNumber)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (v []byte, err error) {
+	err = c.intercept(ctx, "eth_getCode", func() error {
+		v, err = c.client.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (v uint64, err error) {
+	err = c.intercept(ctx, "eth_getTransactionCount", func() error {
+		v, err = c.client.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) PendingBalanceAt(ctx context.Context, account common.Address) (v *big.Int, err error) {
+	err = c.intercept(ctx, "eth_getBalance", func() error {
+		v, err = c.client.PendingBalanceAt(ctx, account)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) (v []byte, err error) {
+	err = c.intercept(ctx, "eth_getStorageAt", func() error {
+		v, err = c.client.PendingStorageAt(ctx, account, key)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) PendingCodeAt(ctx context.Context, account common.Address) (v []byte, err error) {
+	err = c.intercept(ctx, "eth_getCode", func() error {
+		v, err = c.client.PendingCodeAt(ctx, account)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) PendingNonceAt(ctx context.Context, account common.Address) (v uint64, err error) {
+	err = c.intercept(ctx, "eth_getTransactionCount", func() error {
+		v, err = c.client.PendingNonceAt(ctx, account)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) PendingTransactionCount(ctx context.Context) (v uint, err error) {
+	err = c.intercept(ctx, "eth_getBlockTransactionCountByNumber", func() error {
+		v, err = c.client.PendingTransactionCount(ctx)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (v uint64, err error) {
+	err = c.intercept(ctx, "eth_estimateGas", func() error {
+		v, err = c.client.EstimateGas(ctx, call)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) SuggestGasPrice(ctx context.Context) (v *big.Int, err error) {
+	err = c.intercept(ctx, "eth_gasPrice", func() error {
+		v, err = c.client.SuggestGasPrice(ctx)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) TransactionByHash(ctx context.Context, txHash common.Hash) (v *types.Transaction, pending bool, err error) {
+	err = c.intercept(ctx, "eth_getTransactionByHash", func() error {
+		v, pending, err = c.client.TransactionByHash(ctx, txHash)
+		return err
+	})
+	return v, pending, err
+}
+
+func (c *interceptedClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (v *types.Receipt, err error) {
+	err = c.intercept(ctx, "eth_getTransactionReceipt", func() error {
+		v, err = c.client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return v, err
+}
+
+func (c *interceptedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return c.intercept(ctx, "eth_sendRawTransaction", func() error {
+		return c.client.SendTransaction(ctx, tx)
+	})
+}
@@ -0,0 +1,59 @@
+package wallet
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimits hands out one token bucket per RPC URL so that every wallet
+// dialing the same endpoint shares its request budget.
+type RateLimits struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*rate.Limiter
+}
+
+func NewRateLimits(requestsPerSecond float64, burst int) *RateLimits {
+	limit := rate.Inf
+	if requestsPerSecond > 0 {
+		limit = rate.Limit(requestsPerSecond)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimits{
+		limit:    limit,
+		burst:    burst,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+func (l *RateLimits) Limiter(rpcURL string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, ok := l.limiters[rpcURL]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[rpcURL] = limiter
+	}
+	return limiter
+}
+
+func (l *RateLimits) Dialer(dial Dialer) Dialer {
+	if dial == nil {
+		dial = DialRPC
+	}
+	return func(ctx context.Context, rpcURL string) (EthClient, error) {
+		limiter := l.Limiter(rpcURL)
+		return interceptDialer(dial, func(ctx context.Context, method string, call func() error) error {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			return call()
+		})(ctx, rpcURL)
+	}
+}
@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var ErrChainIDMismatch = errors.New("chain ID mismatch")
+
+type EthClient interface {
+	ethereum.ChainIDReader
+	ethereum.BlockNumberReader
+	ethereum.ChainStateReader
+	ethereum.PendingStateReader
+	ethereum.GasEstimator
+	ethereum.GasPricer
+	ethereum.TransactionReader
+	ethereum.TransactionSender
+}
+
+type Dialer func(ctx context.Context, rpcURL string) (EthClient, error)
+
+func DialRPC(ctx context.Context, rpcURL string) (EthClient, error) {
+	return ethclient.DialContext(ctx, rpcURL)
+}
+
+type rpcClient struct {
+	EthClient
+	chainID int64
+}
+
+// SetDialer replaces how the wallet connects to RPC endpoints, e.g. to use a simulated backend.
+func (w *Wallet) SetDialer(dial Dialer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dial = dial
+}
+
+func (w *Wallet) SetPollInterval(interval time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pollInterval = interval
+}
+
+func (w *Wallet) client(ctx context.Context, rpcURL string) (*rpcClient, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if c, ok := w.clients[rpcURL]; ok {
+		return c, nil
+	}
+
+	dial := w.dial
+	if dial == nil {
+		dial = DialRPC
+	}
+	ec, err := dial(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %v", err)
+	}
+
+	if w.clients == nil {
+		w.clients = make(map[string]*rpcClient)
+	}
+	c := &rpcClient{EthClient: ec}
+	w.clients[rpcURL] = c
+	return c, nil
+}
+
+func (w *Wallet) ChainID(ctx context.Context, rpcURL string) (int64, error) {
+	c, err := w.client(ctx, rpcURL)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	cached := c.chainID
+	w.mu.Unlock()
+	if cached != 0 {
+		return cached, nil
+	}
+
+	chainID, err := c.EthClient.ChainID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query eth_chainId: %v", err)
+	}
+	slog.Debug("queried chain ID", "rpc_url", rpcURL, "chain_id", chainID)
+
+	w.mu.Lock()
+	c.chainID = chainID.Int64()
+	w.mu.Unlock()
+
+	return chainID.Int64(), nil
+}
+
+func (w *Wallet) VerifyChainID(ctx context.Context, rpcURL string, expected int64) error {
+	remote, err := w.ChainID(ctx, rpcURL)
+	if err != nil {
+		return err
+	}
+	if remote != expected {
+		return fmt.Errorf("%w: expected %d but %s reports %d", ErrChainIDMismatch, expected, rpcURL, remote)
+	}
+	return nil
+}
+
+func (w *Wallet) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for url, c := range w.clients {
+		if closer, ok := c.EthClient.(interface{ Close() }); ok {
+			closer.Close()
+		}
+		delete(w.clients, url)
+	}
+}
@@ -0,0 +1,66 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrSignatureMismatch = errors.New("signature does not match address")
+
+func HashPersonalMessage(message string) common.Hash {
+	msg := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
+	return crypto.Keccak256Hash([]byte(msg))
+}
+
+// RecoverAddress returns the signer of an EIP-191 personal_sign signature.
+// The recovery byte may be either 0/1 or 27/28.
+func RecoverAddress(message, signature string) (string, error) {
+	return recoverDigest(HashPersonalMessage(message), signature)
+}
+
+func recoverDigest(digest common.Hash, signature string) (string, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature encoding: %v", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("invalid signature length: got %d bytes, want %d", len(sig), crypto.SignatureLength)
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		sig[64] = v - 27
+	}
+	if sig[64] != 0 && sig[64] != 1 {
+		return "", fmt.Errorf("invalid signature recovery id: %d", v)
+	}
+
+	pub, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover public key: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub).Hex(), nil
+}
+
+func VerifySignature(address, message, signature string) error {
+	return verifyDigest(address, HashPersonalMessage(message), signature)
+}
+
+func verifyDigest(address string, digest common.Hash, signature string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid address: %s", address)
+	}
+
+	recovered, err := recoverDigest(digest, signature)
+	if err != nil {
+		return err
+	}
+	if common.HexToAddress(recovered) != common.HexToAddress(address) {
+		return fmt.Errorf("%w: recovered %s, expected %s", ErrSignatureMismatch, recovered, address)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const testPrivateKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func TestSignMessageRecoversToAddress(t *testing.T) {
+	w, err := NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	message := "Welcome to AICraft!\n\nNonce: 1234"
+	signature, err := w.SignMessage(message)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+
+	recovered, err := RecoverAddress(message, signature)
+	if err != nil {
+		t.Fatalf("RecoverAddress: %v", err)
+	}
+	if recovered != w.GetAddress() {
+		t.Fatalf("recovered %s, want %s", recovered, w.GetAddress())
+	}
+	if err := VerifySignature(w.GetAddress(), message, signature); err != nil {
+		t.Fatalf("VerifySignature: %v", err)
+	}
+}
+
+func TestRecoverAddressAcceptsBothRecoveryIDForms(t *testing.T) {
+	w, err := NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	message := "hello"
+	signature, err := w.SignMessage(message)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+
+	sig := hexutil.MustDecode(signature)
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Fatalf("SignMessage produced v=%d, want 27 or 28", sig[64])
+	}
+	sig[64] -= 27
+
+	if err := VerifySignature(w.GetAddress(), message, hexutil.Encode(sig)); err != nil {
+		t.Fatalf("VerifySignature with v in 0/1: %v", err)
+	}
+}
+
+func TestVerifySignatureRejectsMismatch(t *testing.T) {
+	w, err := NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	signature, err := w.SignMessage("original")
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+
+	err = VerifySignature(w.GetAddress(), "tampered", signature)
+	if !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("VerifySignature on tampered message: got %v, want ErrSignatureMismatch", err)
+	}
+
+	sig := hexutil.MustDecode(signature)
+	sig[64] = 5
+	if err := VerifySignature(w.GetAddress(), "original", hexutil.Encode(sig)); err == nil {
+		t.Fatal("VerifySignature accepted invalid recovery id")
+	}
+
+	if err := VerifySignature(w.GetAddress(), "original", "0x1234"); err == nil {
+		t.Fatal("VerifySignature accepted short signature")
+	}
+}
@@ -0,0 +1,96 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const eip712Domain = "EIP712Domain"
+
+// domainFields lists the EIP712Domain members in the order EIP-712 defines.
+var domainFields = []apitypes.Type{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+	{Name: "salt", Type: "bytes32"},
+}
+
+// SignTypedData signs EIP-712 typed data, i.e. keccak256("\x19\x01" ||
+// domainSeparator || hashStruct(message)), returning a 65-byte signature
+// with a 27/28 recovery byte like SignMessage.
+func (w *Wallet) SignTypedData(data apitypes.TypedData) (string, error) {
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		return "", err
+	}
+	return w.signDigest(digest)
+}
+
+// TypedDataHash returns the EIP-712 digest of data. When data.Types has no
+// EIP712Domain entry it is derived from the fields set in data.Domain, so
+// servers may omit it.
+func TypedDataHash(data apitypes.TypedData) (common.Hash, error) {
+	if data.PrimaryType == "" || data.PrimaryType == eip712Domain {
+		return common.Hash{}, errors.New("typed data has no primary type to sign")
+	}
+	if _, ok := data.Types[data.PrimaryType]; !ok {
+		return common.Hash{}, fmt.Errorf("typed data does not define its primary type %s", data.PrimaryType)
+	}
+	data.Types = withDomainType(data.Types, data.Domain)
+
+	digest, _, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+	return common.BytesToHash(digest), nil
+}
+
+// DomainSeparator returns hashStruct(EIP712Domain) for domain.
+func DomainSeparator(domain apitypes.TypedDataDomain) (common.Hash, error) {
+	data := apitypes.TypedData{Types: withDomainType(nil, domain), Domain: domain}
+	separator, err := data.HashStruct(eip712Domain, domain.Map())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash typed data domain: %v", err)
+	}
+	return common.BytesToHash(separator), nil
+}
+
+func withDomainType(types apitypes.Types, domain apitypes.TypedDataDomain) apitypes.Types {
+	if _, ok := types[eip712Domain]; ok {
+		return types
+	}
+	present := domain.Map()
+	derived := make(apitypes.Types, len(types)+1)
+	for name, fields := range types {
+		derived[name] = fields
+	}
+	fields := []apitypes.Type{}
+	for _, field := range domainFields {
+		if _, ok := present[field.Name]; ok {
+			fields = append(fields, field)
+		}
+	}
+	derived[eip712Domain] = fields
+	return derived
+}
+
+// RecoverTypedDataSigner returns the address that produced signature over data.
+func RecoverTypedDataSigner(data apitypes.TypedData, signature string) (string, error) {
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		return "", err
+	}
+	return recoverDigest(digest, signature)
+}
+
+func VerifyTypedData(address string, data apitypes.TypedData, signature string) error {
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		return err
+	}
+	return verifyDigest(address, digest, signature)
+}
@@ -0,0 +1,106 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// mailTypedData is the example from the EIP-712 specification.
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func loadMail(t *testing.T) apitypes.TypedData {
+	t.Helper()
+	var data apitypes.TypedData
+	if err := json.Unmarshal([]byte(mailTypedData), &data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return data
+}
+
+func TestTypedDataHashMatchesSpecExample(t *testing.T) {
+	data := loadMail(t)
+
+	separator, err := DomainSeparator(data.Domain)
+	if err != nil {
+		t.Fatalf("DomainSeparator: %v", err)
+	}
+	if want := "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; separator.Hex() != want {
+		t.Fatalf("domain separator = %s, want %s", separator.Hex(), want)
+	}
+
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		t.Fatalf("TypedDataHash: %v", err)
+	}
+	if want := "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; digest.Hex() != want {
+		t.Fatalf("digest = %s, want %s", digest.Hex(), want)
+	}
+
+	delete(data.Types, "EIP712Domain")
+	derived, err := TypedDataHash(data)
+	if err != nil {
+		t.Fatalf("TypedDataHash without EIP712Domain: %v", err)
+	}
+	if derived != digest {
+		t.Fatalf("digest with derived domain type = %s, want %s", derived.Hex(), digest.Hex())
+	}
+}
+
+func TestSignTypedDataRecoversToAddress(t *testing.T) {
+	w, err := NewWallet(testPrivateKey)
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	data := loadMail(t)
+
+	signature, err := w.SignTypedData(data)
+	if err != nil {
+		t.Fatalf("SignTypedData: %v", err)
+	}
+	if err := VerifyTypedData(w.GetAddress(), data, signature); err != nil {
+		t.Fatalf("VerifyTypedData: %v", err)
+	}
+
+	data.Message["contents"] = "Hello, Eve!"
+	if err := VerifyTypedData(w.GetAddress(), data, signature); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("VerifyTypedData with altered message = %v, want %v", err, ErrSignatureMismatch)
+	}
+
+	data.PrimaryType = ""
+	if _, err := w.SignTypedData(data); err == nil {
+		t.Fatal("SignTypedData accepted typed data without a primary type")
+	}
+}
@@ -0,0 +1,32 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDecodeVoteDataInvertsPrepareVoteData(t *testing.T) {
+	data, err := prepareVoteData("678dbb6579af53b8da5ddf3d", 3, "order-1", `{"candidateID":"678dbb6579af53b8da5ddf3d"}`, "0x1111", "0x2222")
+	if err != nil {
+		t.Fatalf("prepareVoteData: %v", err)
+	}
+
+	call, err := DecodeVoteData(data)
+	if err != nil {
+		t.Fatalf("DecodeVoteData: %v", err)
+	}
+	if call.CandidateID != "678dbb6579af53b8da5ddf3d" || call.FeedAmount.Int64() != 3 || call.RequestID != "order-1" {
+		t.Fatalf("call = %+v", call)
+	}
+	if call.RequestData != `{"candidateID":"678dbb6579af53b8da5ddf3d"}` {
+		t.Fatalf("request data = %q", call.RequestData)
+	}
+	if !bytes.Equal(call.UserHashedMessage, []byte{0x11, 0x11}) || !bytes.Equal(call.IntegritySignature, []byte{0x22, 0x22}) {
+		t.Fatalf("hashed message = %s, signature = %s", call.UserHashedMessage, call.IntegritySignature)
+	}
+
+	if _, err := DecodeVoteData([]byte{0xa9, 0x05, 0x9c, 0xbb}); !errors.Is(err, ErrNotFeedCall) {
+		t.Fatalf("DecodeVoteData(transfer selector) = %v, want %v", err, ErrNotFeedCall)
+	}
+}
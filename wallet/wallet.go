@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -13,18 +15,28 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
+// FeedSignature is the contract function that prepareVoteData encodes calls to.
+const FeedSignature = "feed(string,uint256,string,string,bytes,bytes)"
+
 type Signer interface {
 	GetAddress() string
 	SignMessage(message string) (string, error)
+	SignTypedData(data apitypes.TypedData) (string, error)
 	CreateVoteTransaction(rpcURL, contractAddress, candidateID string, feedAmount int, chainID int64, requestID, requestData, userHashedMessage, integritySignature string) (string, error)
 	WaitForTransactionReceipt(rpcURL, txHash string) (*types.Receipt, error)
 }
 
 type Wallet struct {
 	privateKey *ecdsa.PrivateKey
+
+	mu           sync.Mutex
+	clients      map[string]*rpcClient
+	dial         Dialer
+	pollInterval time.Duration
+	policy       *ContractPolicy
 }
 
 func NewWallet(privateKeyHex string) (*Wallet, error) {
@@ -45,10 +57,11 @@ func (w *Wallet) GetAddress() string {
 }
 
 func (w *Wallet) SignMessage(message string) (string, error) {
-	msg := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
-	msgHash := crypto.Keccak256Hash([]byte(msg))
+	return w.signDigest(HashPersonalMessage(message))
+}
 
-	signature, err := crypto.Sign(msgHash.Bytes(), w.privateKey)
+func (w *Wallet) signDigest(digest common.Hash) (string, error) {
+	signature, err := crypto.Sign(digest.Bytes(), w.privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign message: %v", err)
 	}
@@ -58,13 +71,19 @@ func (w *Wallet) SignMessage(message string) (string, error) {
 }
 
 func (w *Wallet) CreateVoteTransaction(rpcURL, contractAddress, candidateID string, feedAmount int, chainID int64, requestID, requestData, userHashedMessage, integritySignature string) (string, error) {
-	client, err := ethclient.Dial(rpcURL)
+	client, err := w.client(context.Background(), rpcURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to RPC: %v", err)
+		return "", err
+	}
+
+	if err := w.VerifyChainID(context.Background(), rpcURL, chainID); err != nil {
+		return "", fmt.Errorf("refusing to sign: %w", err)
 	}
-	defer client.Close()
 
 	contractAddr := common.HexToAddress(contractAddress)
+	if err := w.CheckContract(context.Background(), rpcURL, chainID, contractAddr); err != nil {
+		return "", fmt.Errorf("refusing to sign: %w", err)
+	}
 	fromAddress := common.HexToAddress(w.GetAddress())
 
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
@@ -85,6 +104,8 @@ func (w *Wallet) CreateVoteTransaction(rpcURL, contractAddress, candidateID stri
 		return "", fmt.Errorf("failed to prepare transaction data: %v", err)
 	}
 
+	slog.Debug("prepared vote transaction", "from", fromAddress.Hex(), "to", contractAddr.Hex(), "nonce", nonce, "base_fee", baseFee, "max_fee", maxFeePerGas)
+
 	gasLimit, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
 		From:  fromAddress,
 		To:    &contractAddr,
@@ -92,6 +113,7 @@ func (w *Wallet) CreateVoteTransaction(rpcURL, contractAddress, candidateID stri
 		Data:  data,
 	})
 	if err != nil {
+		slog.Warn("gas estimation failed, using fallback gas limit", "error", err, "gas_limit", 100000)
 		gasLimit = 100000 
 	} else {
 		gasLimit = gasLimit * 110 / 100
@@ -120,34 +142,53 @@ func (w *Wallet) CreateVoteTransaction(rpcURL, contractAddress, candidateID stri
 	if err != nil {
 		return "", fmt.Errorf("failed to send transaction: %v", err)
 	}
+	slog.Debug("sent vote transaction", "tx_hash", signedTx.Hash().Hex(), "gas_limit", gasLimit, "nonce", nonce)
 
 	return signedTx.Hash().Hex(), nil
 }
 
 func (w *Wallet) WaitForTransactionReceipt(rpcURL, txHash string) (*types.Receipt, error) {
-	client, err := ethclient.Dial(rpcURL)
+	return w.WaitForTransactionReceiptContext(context.Background(), rpcURL, txHash)
+}
+
+// WaitForTransactionReceiptContext is WaitForTransactionReceipt but gives up
+// as soon as ctx is done.
+func (w *Wallet) WaitForTransactionReceiptContext(ctx context.Context, rpcURL, txHash string) (*types.Receipt, error) {
+	client, err := w.client(ctx, rpcURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RPC: %v", err)
+		return nil, err
 	}
-	defer client.Close()
 
 	hash := common.HexToHash(txHash)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	w.mu.Lock()
+	pollInterval := w.pollInterval
+	w.mu.Unlock()
+	if pollInterval <= 0 {
+		pollInterval = 2 * time.Second
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
 	for {
-		receipt, err := client.TransactionReceipt(ctx, hash)
+		receipt, err := client.TransactionReceipt(waitCtx, hash)
 		if err == nil {
 			return receipt, nil
 		}
-		if err == ethereum.NotFound {
+		if err == ethereum.NotFound || isIndexingInProgress(err) {
+			slog.Debug("transaction receipt not yet available", "tx_hash", txHash)
 			select {
-			case <-time.After(2 * time.Second):
+			case <-time.After(pollInterval):
 				continue
-			case <-ctx.Done():
+			case <-waitCtx.Done():
+				if err := ctx.Err(); err != nil {
+					return nil, fmt.Errorf("stopped waiting for transaction receipt: %w", err)
+				}
 				return nil, fmt.Errorf("timeout waiting for transaction receipt")
 			}
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("stopped waiting for transaction receipt: %w", ctxErr)
 		} else {
 			return nil, fmt.Errorf("failed to get receipt: %v", err)
 		}
@@ -155,7 +196,7 @@ func (w *Wallet) WaitForTransactionReceipt(rpcURL, txHash string) (*types.Receip
 }
 
 func prepareVoteData(candidateID string, feedAmount int, requestID, requestData, userHashedMessage, integritySignature string) ([]byte, error) {
-	methodSig := crypto.Keccak256([]byte("feed(string,uint256,string,string,bytes,bytes)"))[:4]
+	methodSig := crypto.Keccak256([]byte(FeedSignature))[:4]
 
 	var data []byte
 	data = append(data, methodSig...)
@@ -224,3 +265,7 @@ func prepareVoteData(candidateID string, feedAmount int, requestID, requestData,
 
 	return data, nil
 }
+
+func isIndexingInProgress(err error) bool {
+	return strings.Contains(err.Error(), "transaction indexing is in progress")
+}